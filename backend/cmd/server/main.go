@@ -4,7 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,11 +12,14 @@ import (
 	"time"
 
 	"github.com/lucas/promptlands/internal/api"
+	"github.com/lucas/promptlands/internal/bots"
 	"github.com/lucas/promptlands/internal/config"
 	"github.com/lucas/promptlands/internal/db"
 	"github.com/lucas/promptlands/internal/game"
 	"github.com/lucas/promptlands/internal/game/actions"
 	"github.com/lucas/promptlands/internal/llm"
+	"github.com/lucas/promptlands/internal/logging"
+	"github.com/lucas/promptlands/internal/metrics"
 	"github.com/lucas/promptlands/internal/ws"
 )
 
@@ -24,19 +27,36 @@ func main() {
 	configPath := flag.String("config", "config.yaml", "path to config file")
 	devMode := flag.Bool("dev", false, "enable development mode with mock LLM")
 	noDB := flag.Bool("no-db", false, "run without database (in-memory only)")
+	record := flag.String("record", "", "record LLM responses to a cassette file")
+	replay := flag.String("replay", "", "replay LLM responses from a cassette file")
 	flag.Parse()
 
 	// Load configuration
-	cfg, err := config.Load(*configPath)
-	if err != nil {
-		log.Printf("Failed to load config from %s, using defaults: %v", *configPath, err)
+	cfg, loadErr := config.Load(*configPath)
+	if loadErr != nil {
 		cfg = config.Default()
+		cfg.LoadEnv()
+	}
+
+	// Initialize logging
+	if err := logging.Setup(os.Stderr, cfg.Log.Format, cfg.Log.Level); err != nil {
+		logging.Setup(os.Stderr, "text", "info")
+		slog.Warn("Invalid log config, using defaults", "error", err)
+	}
+	if loadErr != nil {
+		slog.Warn("Failed to load config, using defaults", "path", *configPath, "error", loadErr)
 	}
 
 	if *devMode {
 		cfg.Dev.Enabled = true
 		cfg.Dev.MockLLM = true
-		log.Println("Development mode enabled with mock LLM")
+		slog.Info("Development mode enabled with mock LLM")
+	}
+	if *record != "" {
+		cfg.LLM.Record = *record
+	}
+	if *replay != "" {
+		cfg.LLM.Replay = *replay
 	}
 
 	// Initialize database connections
@@ -44,17 +64,17 @@ func main() {
 	var redis *db.Redis
 
 	if *noDB || cfg.Dev.Enabled {
-		log.Println("Running without database (in-memory mode)")
+		slog.Info("Running without database (in-memory mode)")
 	} else {
 		var err error
 		postgres, err = db.NewPostgres(cfg.Database.PostgresURL)
 		if err != nil {
-			log.Printf("Warning: Failed to connect to PostgreSQL: %v", err)
+			slog.Warn("Failed to connect to PostgreSQL", "error", err)
 		}
 
 		redis, err = db.NewRedis(cfg.Database.RedisURL)
 		if err != nil {
-			log.Printf("Warning: Failed to connect to Redis: %v", err)
+			slog.Warn("Failed to connect to Redis", "error", err)
 		}
 	}
 	defer postgres.Close()
@@ -62,11 +82,36 @@ func main() {
 
 	// Initialize LLM client
 	var llmClient game.LLMClient
-	if cfg.Dev.MockLLM {
+	if cfg.LLM.Replay != "" {
+		replayClient, err := llm.NewReplayClient(cfg.LLM.Replay)
+		if err != nil {
+			slog.Error("Failed to load cassette", "path", cfg.LLM.Replay, "error", err)
+			os.Exit(1)
+		}
+		llmClient = replayClient
+		slog.Info("Replaying LLM responses", "path", cfg.LLM.Replay)
+	} else if cfg.Dev.MockLLM {
 		llmClient = llm.NewMockClient()
 	} else {
-		llmClient = llm.NewGeminiClient(cfg.LLM.APIKey, cfg.LLM.Model, cfg.LLM.Timeout)
+		registry, err := llm.NewClientRegistry(cfg.LLM)
+		if err != nil {
+			slog.Error("Invalid LLM config", "error", err)
+			os.Exit(1)
+		}
+		llmClient = llm.NewScheduler(registry, cfg.LLM.Scheduler, cfg.LLM.Provider)
+		slog.Info("LLM client ready", "provider", cfg.LLM.Provider, "model", cfg.LLM.Model)
 	}
+	if cfg.LLM.Record != "" && cfg.LLM.Replay == "" {
+		recorder, err := llm.NewRecordingClient(llmClient, cfg.LLM.Record)
+		if err != nil {
+			slog.Error("Failed to create cassette", "path", cfg.LLM.Record, "error", err)
+			os.Exit(1)
+		}
+		defer recorder.Close()
+		llmClient = recorder
+		slog.Info("Recording LLM responses", "path", cfg.LLM.Record)
+	}
+	llmClient = bots.NewRouter(llmClient) // Bots run locally in every mode and are never recorded
 
 	// Initialize prompt builder
 	promptBuilder := llm.NewPromptBuilder()
@@ -83,10 +128,33 @@ func main() {
 	gameManager := game.NewManagerWithBalance(cfg.Game, cfg.Balance, llmClient, promptBuilder, hub, postgres, redis)
 	gameManager.SetHandlerRegistry(handlerRegistry)
 
+	// Enable live commentary when the LLM backend can generate free text
+	if cfg.Game.Narrator.Enabled {
+		if gen, ok := llmClient.(game.TextGenerator); ok {
+			gameManager.SetNarrator(gen)
+		} else {
+			slog.Warn("Narrator enabled but the LLM client can't generate text")
+		}
+	}
+
+	// Expose live counts on /metrics
+	gameManager.RegisterMetrics(metrics.Default)
+	hub.RegisterMetrics(metrics.Default)
+
+	// Start the lifecycle reaper for finished, idle and queued games
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	go gameManager.RunReaper(reaperCtx)
+
+	// Attach tick phase timings to tick updates in dev mode
+	if cfg.Dev.Enabled {
+		gameManager.SetProfileUpdates(true)
+	}
+
 	// Set pause mode if configured
 	if cfg.Dev.PauseTick {
 		gameManager.SetPauseByDefault(true)
-		log.Println("Pause tick enabled: games will start paused (use ForceTick or Resume)")
+		slog.Info("Pause tick enabled: games will start paused (use ForceTick or Resume)")
 	}
 
 	// Set up HTTP routes
@@ -103,9 +171,10 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Server starting on %s:%d", cfg.Server.Host, cfg.Server.Port)
+		slog.Info("Server starting", "host", cfg.Server.Host, "port", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
+			slog.Error("Server failed", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -114,7 +183,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server...")
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -124,8 +193,9 @@ func main() {
 	gameManager.StopAll()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		slog.Error("Server forced to shutdown", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("Server exited")
+	slog.Info("Server exited")
 }
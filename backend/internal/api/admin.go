@@ -0,0 +1,208 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/logging"
+)
+
+// maxAnnouncementLength caps admin announcement messages
+const maxAnnouncementLength = 500
+
+// adminGame is one entry of the admin game list
+type adminGame struct {
+	*game.GameInfo
+	Private     bool          `json:"private"`
+	Viewers     int           `json:"viewers"`
+	MemoryBytes int64         `json:"memory_bytes"`
+	LLM         game.LLMStats `json:"llm"`
+	DebugLog    bool          `json:"debug_log"`
+}
+
+// adminAuth rejects requests that don't carry the configured admin token
+func (h *Handler) adminAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Admin.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// AdminListGames returns every game, including private ones, with memory
+// and LLM usage
+func (h *Handler) AdminListGames(w http.ResponseWriter, r *http.Request) {
+	engines := h.gameManager.Games()
+	games := make([]adminGame, len(engines))
+	for i, engine := range engines {
+		info := engine.Info()
+		games[i] = adminGame{
+			GameInfo:    info,
+			Private:     info.Visibility != game.VisibilityPublic,
+			Viewers:     h.hub.GetGameClientCount(engine.ID),
+			MemoryBytes: engine.MemoryEstimate(),
+			LLM:         engine.LLMStats(),
+			DebugLog:    logging.GameDebug(engine.ID),
+		}
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+// AdminStatus returns server-wide counters
+func (h *Handler) AdminStatus(w http.ResponseWriter, r *http.Request) {
+	engines := h.gameManager.Games()
+	byStatus := make(map[game.GameStatus]int)
+	var memory int64
+	for _, engine := range engines {
+		byStatus[engine.GetStatus()]++
+		memory += engine.MemoryEstimate()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"draining":     h.gameManager.IsDraining(),
+		"games":        len(engines),
+		"by_status":    byStatus,
+		"clients":      h.hub.GetClientCount(),
+		"memory_bytes": memory,
+	})
+}
+
+// AdminStopGame force-stops a game and notifies its clients
+func (h *Handler) AdminStopGame(w http.ResponseWriter, r *http.Request) {
+	gameID, ok := h.parseGameID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.gameManager.StopGame(gameID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.hub.BroadcastToGame(gameID, map[string]interface{}{
+		"type":   "game_stopped",
+		"reason": "stopped by an administrator",
+	})
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "stopped",
+	})
+}
+
+// AdminRemoveGame stops a game, notifies its clients and removes it
+func (h *Handler) AdminRemoveGame(w http.ResponseWriter, r *http.Request) {
+	gameID, ok := h.parseGameID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.gameManager.GetGame(gameID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.hub.BroadcastToGame(gameID, map[string]interface{}{
+		"type":   "game_stopped",
+		"reason": "removed by an administrator",
+	})
+	h.gameManager.RemoveGame(gameID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetGameDebug switches debug logging for a single game on or off
+func (h *Handler) SetGameDebug(w http.ResponseWriter, r *http.Request) {
+	gameID, ok := h.parseGameID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.gameManager.GetGame(gameID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	logging.SetGameDebug(gameID, *req.Enabled)
+	writeJSON(w, http.StatusOK, map[string]bool{
+		"debug_log": *req.Enabled,
+	})
+}
+
+// AdminAnnounce broadcasts a server announcement to one game or all games
+func (h *Handler) AdminAnnounce(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Message string     `json:"message"`
+		GameID  *uuid.UUID `json:"game_id,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+	if len(req.Message) > maxAnnouncementLength {
+		writeError(w, http.StatusBadRequest, "message is too long")
+		return
+	}
+
+	var targets []uuid.UUID
+	if req.GameID != nil {
+		if _, err := h.gameManager.GetGame(*req.GameID); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		targets = []uuid.UUID{*req.GameID}
+	} else {
+		for _, engine := range h.gameManager.Games() {
+			targets = append(targets, engine.ID)
+		}
+	}
+
+	announcement := map[string]interface{}{
+		"type":    "announcement",
+		"message": req.Message,
+		"sent_at": time.Now().UTC(),
+	}
+	for _, gameID := range targets {
+		h.hub.BroadcastToGame(gameID, announcement)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{
+		"games": len(targets),
+	})
+}
+
+// AdminDrain turns maintenance drain mode on or off. While draining,
+// running games continue but no games can be created or joined.
+func (h *Handler) AdminDrain(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Draining *bool `json:"draining"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Draining == nil {
+		writeError(w, http.StatusBadRequest, "draining is required")
+		return
+	}
+
+	h.gameManager.SetDraining(*req.Draining)
+	writeJSON(w, http.StatusOK, map[string]bool{
+		"draining": *req.Draining,
+	})
+}
@@ -1,30 +1,41 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/config"
 	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/game/render"
+	"github.com/lucas/promptlands/internal/game/worldgen"
+	"github.com/lucas/promptlands/internal/logging"
 	"github.com/lucas/promptlands/internal/ws"
 )
 
 // Handler contains HTTP handler methods
 type Handler struct {
-	gameManager *game.Manager
-	hub         *ws.Hub
-	wsHandler   *ws.Handler
-	cfg         *config.Config
+	gameManager   *game.Manager
+	hub           *ws.Hub
+	wsHandler     *ws.Handler
+	cfg           *config.Config
+	inviteLimiter *rateLimiter
 }
 
 // NewHandler creates a new API handler
 func NewHandler(gameManager *game.Manager, hub *ws.Hub, cfg *config.Config) *Handler {
 	h := &Handler{
-		gameManager: gameManager,
-		hub:         hub,
-		cfg:         cfg,
+		gameManager:   gameManager,
+		hub:           hub,
+		cfg:           cfg,
+		inviteLimiter: newRateLimiter(inviteLookupsPerMinute, inviteLookupBurst),
 	}
 	h.wsHandler = ws.NewHandler(hub, &gameStateAdapter{gameManager})
 	return h
@@ -40,12 +51,11 @@ func (a *gameStateAdapter) GetFullState(gameID uuid.UUID, playerAgentID *uuid.UU
 	if err != nil {
 		return nil, err
 	}
+	a.manager.NoteViewer(gameID)
 	// If player agent ID is provided, return state with visible tiles
 	if playerAgentID != nil {
-		log.Printf("gameStateAdapter.GetFullState: calling GetFullStateForPlayer with playerAgentID=%s", *playerAgentID)
 		return engine.GetFullStateForPlayer(*playerAgentID), nil
 	}
-	log.Printf("gameStateAdapter.GetFullState: no playerAgentID, returning basic state")
 	return engine.GetFullState(), nil
 }
 
@@ -61,6 +71,7 @@ func (h *Handler) parseGameID(w http.ResponseWriter, r *http.Request) (uuid.UUID
 }
 
 // getGameEngine parses the game ID and looks up the engine.
+// Private games additionally require an invite or spectator code in ?code=.
 // Returns the engine and true, or writes an error and returns false.
 func (h *Handler) getGameEngine(w http.ResponseWriter, r *http.Request) (*game.Engine, uuid.UUID, bool) {
 	gameID, ok := h.parseGameID(w, r)
@@ -72,48 +83,116 @@ func (h *Handler) getGameEngine(w http.ResponseWriter, r *http.Request) (*game.E
 		writeError(w, http.StatusNotFound, err.Error())
 		return nil, uuid.Nil, false
 	}
+	if lobby := engine.GetLobby(); lobby != nil && !lobby.CanView(r.URL.Query().Get("code")) {
+		writeError(w, http.StatusForbidden, "this game is private")
+		return nil, uuid.Nil, false
+	}
 	return engine, gameID, true
 }
 
 // Health returns server health status
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	status := "ok"
+	if h.gameManager.IsDraining() {
+		status = "draining"
+	}
 	writeJSON(w, http.StatusOK, map[string]string{
-		"status": "ok",
+		"status": status,
 	})
 }
 
-// ListGames returns all active games
+// ListGames returns active games, newest first.
+// Supports ?status=, ?open=true, ?limit= and ?offset= query parameters;
+// the unpaginated match count is returned in the X-Total-Count header.
 func (h *Handler) ListGames(w http.ResponseWriter, r *http.Request) {
-	games := h.gameManager.ListGames()
+	query := r.URL.Query()
+	filter := game.GameFilter{
+		Status: game.GameStatus(query.Get("status")),
+		Open:   query.Get("open") == "true",
+	}
+
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			writeError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+	}
+
+	games, total := h.gameManager.ListGames(filter)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	writeJSON(w, http.StatusOK, games)
 }
 
-// CreateGame creates a new multiplayer game
+// CreateGame creates a new multiplayer game. The optional body carries the
+// host name and per-game settings; the response includes the host token
+// needed for start and kick.
 func (h *Handler) CreateGame(w http.ResponseWriter, r *http.Request) {
-	engine, err := h.gameManager.CreateGame()
+	var req struct {
+		HostName string `json:"host_name"`
+		game.GameSettings
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	engine, err := h.gameManager.CreateGameWithSettings(req.GameSettings, req.HostName)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, lobbyErrorStatus(err), err.Error())
 		return
 	}
 
+	lobby := engine.GetLobby()
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
-		"id":     engine.ID,
-		"status": engine.GetStatus(),
+		"id":             engine.ID,
+		"status":         engine.GetStatus(),
+		"host_token":     lobby.HostToken(),
+		"invite_code":    lobby.InviteCode(),
+		"spectator_code": lobby.SpectatorCode(),
+		"game":           engine.Info(),
+	})
+}
+
+// ResolveInvite looks up the game behind an invite or spectator code.
+// Lookups are rate limited per client IP.
+func (h *Handler) ResolveInvite(w http.ResponseWriter, r *http.Request) {
+	engine, role, err := h.gameManager.FindGameByCode(r.PathValue("code"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"game_id":           engine.ID,
+		"role":              role,
+		"requires_password": role == game.CodeRolePlayer && engine.GetLobby().HasPassword(),
+		"game":              engine.Info(),
 	})
 }
 
 // CreateSingleplayerGame creates a game with AI adversaries
 func (h *Handler) CreateSingleplayerGame(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		PlayerPrompt string   `json:"player_prompt"`
-		PlayerName   string   `json:"player_name"`
-		Adversaries  []string `json:"adversaries"`
-		Seed         int64    `json:"seed,omitempty"`
+		PlayerPrompt string               `json:"player_prompt"`
+		PlayerName   string               `json:"player_name"`
+		PlayerModel  game.ModelSettings   `json:"player_model"`
+		Adversaries  []game.AdversarySpec `json:"adversaries"` // Type names or {"type", "model"} objects
+		Seed         int64                `json:"seed,omitempty"`
 		MapConfig    *struct {
-			Preset     string `json:"preset"`
-			Size       string `json:"size"`
-			CustomSize int    `json:"custom_size"`
-			Seed       int64  `json:"seed"`
+			Preset     string              `json:"preset"`
+			Size       string              `json:"size"`
+			CustomSize int                 `json:"custom_size"`
+			Seed       int64               `json:"seed"`
+			Config     *worldgen.MapConfig `json:"config,omitempty"`   // Full custom map config
+			Authored   *game.AuthoredMap   `json:"authored,omitempty"` // Hand-made map (JSON object or ASCII string)
 		} `json:"map_config,omitempty"`
 	}
 
@@ -128,27 +207,34 @@ func (h *Handler) CreateSingleplayerGame(w http.ResponseWriter, r *http.Request)
 	}
 
 	if len(req.Adversaries) == 0 {
-		req.Adversaries = []string{"aggressive"} // Default adversary
+		req.Adversaries = []game.AdversarySpec{{Type: "aggressive"}} // Default adversary
 	}
 
 	if req.PlayerName == "" {
 		req.PlayerName = "Player"
 	}
 
-	// Build map size override from request
-	var mapSizeOverride string
-	if req.MapConfig != nil && req.MapConfig.Size != "" {
-		mapSizeOverride = req.MapConfig.Size
+	// Build per-game map settings from request
+	settings := game.GameSettings{Seed: req.Seed}
+	if req.MapConfig != nil {
+		settings.MapPreset = req.MapConfig.Preset
+		settings.MapSize = req.MapConfig.Size
+		settings.CustomSize = req.MapConfig.CustomSize
+		settings.MapConfig = req.MapConfig.Config
+		settings.AuthoredMap = req.MapConfig.Authored
+		if req.MapConfig.Seed != 0 {
+			settings.Seed = req.MapConfig.Seed
+		}
 	}
 
-	engine, playerAgentID, err := h.gameManager.CreateSingleplayerGameWithSeed(req.PlayerPrompt, req.Adversaries, req.Seed, mapSizeOverride)
+	engine, playerAgentID, err := h.gameManager.CreateSingleplayerGameWithModels(req.PlayerPrompt, req.PlayerModel, req.Adversaries, settings)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, lobbyErrorStatus(err), err.Error())
 		return
 	}
 
-	// Auto-start singleplayer games
-	if err := engine.Start(); err != nil {
+	// Auto-start singleplayer games (queued when the server is at capacity)
+	if err := h.gameManager.StartGame(engine.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -190,8 +276,12 @@ func (h *Handler) JoinGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		PlayerName   string `json:"player_name"`
-		SystemPrompt string `json:"system_prompt"`
+		PlayerName   string             `json:"player_name"`
+		SystemPrompt string             `json:"system_prompt"`
+		InviteCode   string             `json:"invite_code"`
+		Password     string             `json:"password"`
+		Model        game.ModelSettings `json:"model"`     // Optional provider, model and sampling overrides
+		PlayerID     uuid.UUID          `json:"player_id"` // Optional, a returning player with its player token as a bearer token
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -199,6 +289,11 @@ func (h *Handler) JoinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.PlayerID != uuid.Nil && !h.gameManager.IsPlayer(req.PlayerID, bearerToken(r)) {
+		writeError(w, http.StatusForbidden, game.ErrNotPlayer.Error())
+		return
+	}
+
 	if req.PlayerName == "" {
 		req.PlayerName = "Anonymous"
 	}
@@ -208,28 +303,34 @@ func (h *Handler) JoinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	agent, err := h.gameManager.JoinGame(gameID, req.PlayerName, req.SystemPrompt)
+	agent, err := h.gameManager.JoinGameWithModel(gameID, req.PlayerID, req.PlayerName, req.SystemPrompt, req.InviteCode, req.Password, req.Model)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, lobbyErrorStatus(err), err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"agent_id": agent.ID,
-		"name":     agent.Name,
-		"position": agent.Position,
-	})
+	resp := map[string]interface{}{
+		"agent_id":  agent.ID,
+		"player_id": agent.PlayerID,
+		"name":      agent.Name,
+		"position":  agent.Position,
+	}
+	if engine, err := h.gameManager.GetGame(gameID); err == nil && engine.GetLobby() != nil {
+		resp["player_token"] = engine.GetLobby().PlayerToken(agent.ID)
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// StartGame starts a waiting game
+// StartGame starts a waiting game. Lobby games require the host token as
+// a bearer token and every player to be ready.
 func (h *Handler) StartGame(w http.ResponseWriter, r *http.Request) {
 	gameID, ok := h.parseGameID(w, r)
 	if !ok {
 		return
 	}
 
-	if err := h.gameManager.StartGame(gameID); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.gameManager.StartGameAsHost(gameID, bearerToken(r)); err != nil {
+		writeError(w, lobbyErrorStatus(err), err.Error())
 		return
 	}
 
@@ -238,6 +339,62 @@ func (h *Handler) StartGame(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ReadyUp marks a joined player as ready or not ready. Requires the
+// player token from joining as a bearer token.
+func (h *Handler) ReadyUp(w http.ResponseWriter, r *http.Request) {
+	gameID, ok := h.parseGameID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AgentID uuid.UUID `json:"agent_id"`
+		Ready   *bool     `json:"ready"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ready := req.Ready == nil || *req.Ready
+	if err := h.gameManager.SetReady(gameID, req.AgentID, bearerToken(r), ready); err != nil {
+		writeError(w, lobbyErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"agent_id": req.AgentID,
+		"ready":    ready,
+	})
+}
+
+// KickPlayer removes a player from a waiting game (host only)
+func (h *Handler) KickPlayer(w http.ResponseWriter, r *http.Request) {
+	gameID, ok := h.parseGameID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AgentID uuid.UUID `json:"agent_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.gameManager.KickPlayer(gameID, bearerToken(r), req.AgentID); err != nil {
+		writeError(w, lobbyErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "kicked",
+	})
+}
+
 // GetGameState returns the full current game state
 func (h *Handler) GetGameState(w http.ResponseWriter, r *http.Request) {
 	engine, _, ok := h.getGameEngine(w, r)
@@ -248,6 +405,170 @@ func (h *Handler) GetGameState(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, engine.GetFullState())
 }
 
+// GetMapPNG renders the world with territory overlaid.
+// Supports ?size= for the longest side in pixels.
+func (h *Handler) GetMapPNG(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	opts, ok := parseRenderOptions(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.WriteMapPNG(&buf, engine, opts); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	setRenderCacheHeaders(w, engine)
+	w.Write(buf.Bytes())
+}
+
+// GetTimelapseGIF renders territory growth over the game as an animated GIF.
+// Supports ?size=, ?frames= (max frame count) and ?delay= (100ths of a second).
+func (h *Handler) GetTimelapseGIF(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	opts, ok := parseRenderOptions(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.WriteTimelapseGIF(&buf, engine, opts); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	setRenderCacheHeaders(w, engine)
+	w.Write(buf.Bytes())
+}
+
+// GetMapLegend returns the agent colors used in map.png and timelapse.gif
+func (h *Handler) GetMapLegend(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, render.Legends(engine))
+}
+
+// GetGameStats returns per-agent analytics accumulated so far
+func (h *Handler) GetGameStats(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tick":   engine.GetTick(),
+		"agents": engine.Stats(),
+	})
+}
+
+// GetGameUsage returns the game's LLM token usage and estimated cost per
+// agent and player, and its budget
+func (h *Handler) GetGameUsage(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, engine.Usage())
+}
+
+// GetPlayerUsage returns a player's LLM usage summed over every game.
+// Requires the player token from joining as a bearer token.
+func (h *Handler) GetPlayerUsage(w http.ResponseWriter, r *http.Request) {
+	playerID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid player ID")
+		return
+	}
+
+	usage, err := h.gameManager.PlayerUsage(playerID, bearerToken(r))
+	if err != nil {
+		writeError(w, lobbyErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// GetHighlights returns bookmarked key moments from the game's history
+func (h *Handler) GetHighlights(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	highlights := engine.Highlights()
+	if highlights == nil {
+		highlights = []game.Highlight{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     engine.GetStatus(),
+		"tick":       engine.GetTick(),
+		"highlights": highlights,
+	})
+}
+
+// ExportDataset streams recorded prompts, responses, actions and results as
+// JSON lines for training. Supports ?k= for the reward lookahead in ticks.
+func (h *Handler) ExportDataset(w http.ResponseWriter, r *http.Request) {
+	engine, gameID, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	if !engine.RecordsPrompts() {
+		writeError(w, http.StatusNotFound, "prompt recording is disabled for this game (game.record_prompts)")
+		return
+	}
+
+	k := game.DefaultRewardTicks
+	if v := r.URL.Query().Get("k"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			writeError(w, http.StatusBadRequest, "k must be between 1 and 1000")
+			return
+		}
+		k = n
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="promptlands-%s.jsonl"`, gameID))
+	if err := engine.WriteDataset(w, k); err != nil {
+		slog.Warn("Dataset export failed", logging.KeyGameID, gameID, "error", err)
+	}
+}
+
+// GetCommentary returns the narrator's commentary and end-of-game recap
+func (h *Handler) GetCommentary(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	lines, recap := engine.Commentary()
+	if lines == nil {
+		lines = []game.Commentary{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"lines": lines,
+		"recap": recap,
+	})
+}
+
 // WebSocket handles WebSocket connections
 func (h *Handler) WebSocket(w http.ResponseWriter, r *http.Request) {
 	_, gameID, ok := h.getGameEngine(w, r)
@@ -260,11 +581,9 @@ func (h *Handler) WebSocket(w http.ResponseWriter, r *http.Request) {
 	if playerIDStr := r.URL.Query().Get("player_agent_id"); playerIDStr != "" {
 		if parsed, err := uuid.Parse(playerIDStr); err == nil {
 			playerAgentID = &parsed
-			log.Printf("WebSocket connection with player_agent_id: %s", parsed)
 		}
-	} else {
-		log.Printf("WebSocket connection without player_agent_id")
 	}
+	slog.Debug("WebSocket connection", logging.KeyGameID, gameID, logging.KeyAgentID, playerAgentID)
 
 	h.wsHandler.ServeWS(w, r, gameID, playerAgentID)
 }
@@ -315,8 +634,12 @@ func (h *Handler) ResumeGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	status := "game resumed"
+	if engine, err := h.gameManager.GetGame(gameID); err == nil && engine.IsPaused() {
+		status = "game queued to resume"
+	}
 	writeJSON(w, http.StatusOK, map[string]string{
-		"status": "game resumed",
+		"status": status,
 	})
 }
 
@@ -330,6 +653,32 @@ func (h *Handler) DebugState(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, engine.GetFullState())
 }
 
+// GetProfile returns per-phase timings of recent ticks and their averages
+// (dev only). ?last= limits how many ticks are returned.
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	engine, _, ok := h.getGameEngine(w, r)
+	if !ok {
+		return
+	}
+
+	profiles := engine.Profiles()
+	if v := r.URL.Query().Get("last"); v != "" {
+		last, err := strconv.Atoi(v)
+		if err != nil || last < 0 {
+			writeError(w, http.StatusBadRequest, "invalid last")
+			return
+		}
+		if last < len(profiles) {
+			profiles = profiles[len(profiles)-last:]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ticks":   profiles,
+		"summary": game.SummarizeProfiles(profiles),
+	})
+}
+
 // ListAdversaries returns available AI adversary types
 func (h *Handler) ListAdversaries(w http.ResponseWriter, r *http.Request) {
 	types := game.GetAdversaryTypes()
@@ -346,12 +695,95 @@ func (h *Handler) ListAdversaries(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, adversaries)
 }
 
+// ListMapPresets returns the built-in map presets
+func (h *Handler) ListMapPresets(w http.ResponseWriter, r *http.Request) {
+	registry := worldgen.NewMapConfigRegistry()
+	ids := registry.List()
+	presets := make([]map[string]interface{}, 0, len(ids))
+
+	for _, id := range ids {
+		preset, _ := registry.Get(id)
+		presets = append(presets, map[string]interface{}{
+			"id":          preset.ID,
+			"name":        preset.Name,
+			"description": preset.Description,
+			"theme":       preset.Theme,
+			"size":        preset.Size,
+			"tiles":       preset.GetActualSize(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, presets)
+}
+
 // writeJSON writes a JSON response
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("Failed to encode response: %v", err)
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// parseRenderOptions reads ?size=, ?frames= and ?delay= for rendered images
+func parseRenderOptions(w http.ResponseWriter, r *http.Request) (render.Options, bool) {
+	var opts render.Options
+	query := r.URL.Query()
+	fields := []struct {
+		name string
+		dst  *int
+		max  int
+	}{
+		{"size", &opts.MaxDim, render.MaxDim},
+		{"frames", &opts.MaxFrames, 1000},
+		{"delay", &opts.Delay, 1000},
+	}
+	for _, f := range fields {
+		v := query.Get(f.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > f.max {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be between 1 and %d", f.name, f.max))
+			return opts, false
+		}
+		*f.dst = n
+	}
+	return opts, true
+}
+
+// setRenderCacheHeaders lets clients cache renders of finished games
+func setRenderCacheHeaders(w http.ResponseWriter, engine *game.Engine) {
+	if engine.GetStatus() == game.StatusFinished {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// lobbyErrorStatus maps lobby errors to HTTP status codes
+func lobbyErrorStatus(err error) int {
+	switch err {
+	case game.ErrGameNotFound, game.ErrAgentNotFound:
+		return http.StatusNotFound
+	case game.ErrNotHost, game.ErrNotPlayer, game.ErrInviteRequired, game.ErrWrongPassword:
+		return http.StatusForbidden
+	case game.ErrPlayersNotReady, game.ErrGameAlreadyStarted, game.ErrGameFull:
+		return http.StatusConflict
+	case game.ErrServerDraining:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
 	}
 }
 
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Invite code lookups allowed per client IP, so six-character codes can't
+// be enumerated
+const (
+	inviteLookupsPerMinute = 10
+	inviteLookupBurst      = 5
+	maxLimiterClients      = 10000 // Full buckets are dropped beyond this
+)
+
+// rateLimiter is a token bucket per client IP
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // Tokens per second
+	burst   float64
+	clients map[string]*bucket
+}
+
+// bucket holds one client's tokens as of last
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter allows perMinute requests per client, with bursts of burst
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		clients: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the client's bucket if one is left
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.clients[client]
+	if !ok {
+		if len(l.clients) >= maxLimiterClients {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// prune drops clients whose buckets have refilled. Caller must hold l.mu.
+func (l *rateLimiter) prune(now time.Time) {
+	for client, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// limit rejects requests with 429 once the client's bucket is empty
+func (l *rateLimiter) limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(clientIP(r), time.Now()) {
+			w.Header().Set("Retry-After", "60")
+			writeError(w, http.StatusTooManyRequests, "too many requests")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
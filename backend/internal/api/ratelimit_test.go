@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(60, 2) // One token per second
+	now := time.Now()
+
+	if !l.allow("a", now) || !l.allow("a", now) {
+		t.Fatal("expected the burst to be allowed")
+	}
+	if l.allow("a", now) {
+		t.Error("expected the third request to be limited")
+	}
+	if !l.allow("b", now) {
+		t.Error("expected other clients to have their own bucket")
+	}
+	if !l.allow("a", now.Add(time.Second)) {
+		t.Error("expected a token after refilling")
+	}
+}
+
+func TestRateLimiter_Handler(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	handler := l.limit(func(w http.ResponseWriter, r *http.Request) {})
+
+	codes := make([]int, 2)
+	for i := range codes {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/invites/ABCDEF", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		handler(rec, req)
+		codes[i] = rec.Code
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected 200 then 429, got %v", codes)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lucas/promptlands/internal/config"
 	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/metrics"
 	"github.com/lucas/promptlands/internal/ws"
 )
 
@@ -17,13 +18,32 @@ func NewRouter(gameManager *game.Manager, hub *ws.Hub, cfg *config.Config) http.
 	// Health check
 	mux.HandleFunc("GET /health", handler.Health)
 
+	// Prometheus metrics
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+
 	// Game routes
 	mux.HandleFunc("GET /api/games", handler.ListGames)
 	mux.HandleFunc("POST /api/games", handler.CreateGame)
 	mux.HandleFunc("GET /api/games/{id}", handler.GetGame)
 	mux.HandleFunc("POST /api/games/{id}/join", handler.JoinGame)
 	mux.HandleFunc("POST /api/games/{id}/start", handler.StartGame)
+	mux.HandleFunc("POST /api/games/{id}/ready", handler.ReadyUp)
+	mux.HandleFunc("POST /api/games/{id}/kick", handler.KickPlayer)
 	mux.HandleFunc("GET /api/games/{id}/state", handler.GetGameState)
+	mux.HandleFunc("GET /api/games/{id}/map.png", handler.GetMapPNG)
+	mux.HandleFunc("GET /api/games/{id}/map/legend", handler.GetMapLegend)
+	mux.HandleFunc("GET /api/games/{id}/timelapse.gif", handler.GetTimelapseGIF)
+	mux.HandleFunc("GET /api/games/{id}/stats", handler.GetGameStats)
+	mux.HandleFunc("GET /api/games/{id}/usage", handler.GetGameUsage)
+	mux.HandleFunc("GET /api/games/{id}/commentary", handler.GetCommentary)
+	mux.HandleFunc("GET /api/games/{id}/highlights", handler.GetHighlights)
+	mux.HandleFunc("GET /api/games/{id}/dataset.jsonl", handler.ExportDataset)
+
+	// Players
+	mux.HandleFunc("GET /api/players/{id}/usage", handler.GetPlayerUsage)
+
+	// Invite and spectator codes
+	mux.HandleFunc("GET /api/invites/{code}", handler.inviteLimiter.limit(handler.ResolveInvite))
 
 	// Singleplayer
 	mux.HandleFunc("POST /api/games/singleplayer", handler.CreateSingleplayerGame)
@@ -37,11 +57,27 @@ func NewRouter(gameManager *game.Manager, hub *ws.Hub, cfg *config.Config) http.
 		mux.HandleFunc("POST /api/dev/pause/{id}", handler.PauseGame)
 		mux.HandleFunc("POST /api/dev/resume/{id}", handler.ResumeGame)
 		mux.HandleFunc("GET /api/dev/state/{id}", handler.DebugState)
+		mux.HandleFunc("POST /api/dev/debug/{id}", handler.SetGameDebug)
+		mux.HandleFunc("GET /api/dev/profile/{id}", handler.GetProfile)
+	}
+
+	// Admin routes (only enabled when an admin token is configured)
+	if cfg.Admin.Token != "" {
+		mux.HandleFunc("GET /api/admin/status", handler.adminAuth(handler.AdminStatus))
+		mux.HandleFunc("GET /api/admin/games", handler.adminAuth(handler.AdminListGames))
+		mux.HandleFunc("POST /api/admin/games/{id}/stop", handler.adminAuth(handler.AdminStopGame))
+		mux.HandleFunc("DELETE /api/admin/games/{id}", handler.adminAuth(handler.AdminRemoveGame))
+		mux.HandleFunc("POST /api/admin/games/{id}/debug", handler.adminAuth(handler.SetGameDebug))
+		mux.HandleFunc("POST /api/admin/announce", handler.adminAuth(handler.AdminAnnounce))
+		mux.HandleFunc("POST /api/admin/drain", handler.adminAuth(handler.AdminDrain))
 	}
 
 	// Adversary types
 	mux.HandleFunc("GET /api/adversaries", handler.ListAdversaries)
 
+	// Map presets
+	mux.HandleFunc("GET /api/map-presets", handler.ListMapPresets)
+
 	// Add CORS middleware
 	return corsMiddleware(mux)
 }
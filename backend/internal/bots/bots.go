@@ -0,0 +1,89 @@
+// Package bots provides deterministic rule-based agents that implement
+// game.LLMClient. They read the structured game.AgentContext instead of the
+// prompt text, so they cost nothing to run and serve as offline opponents,
+// baselines for evaluating prompts and load for tests.
+package bots
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Provider is the game.ModelSettings provider that selects a bot; the
+// settings' model names the strategy, e.g. {"provider": "bot", "model": "raider"}
+const Provider = game.BotProvider
+
+// DefaultStrategy is used when no strategy is named
+const DefaultStrategy = "expander"
+
+// Strategy picks an agent's action from its view of the game
+type Strategy func(actx game.AgentContext) game.Action
+
+// Strategies holds the built-in bots by name
+var Strategies = map[string]Strategy{
+	"expander":  Expander,
+	"turtle":    Turtle,
+	"raider":    Raider,
+	"harvester": Harvester,
+}
+
+// Names returns the built-in strategy names in order
+func Names() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Client runs the strategy named by each agent's model settings
+type Client struct{}
+
+// NewClient creates a bot client
+func NewClient() *Client {
+	return &Client{}
+}
+
+// GetAction runs the agent's strategy on the agent context attached by the engine
+func (c *Client) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	actx, ok := game.AgentContextFromContext(ctx)
+	if !ok || actx.Agent == nil {
+		return game.WaitAction(agentID), fmt.Errorf("bots need the agent context")
+	}
+	settings, _ := game.ModelSettingsFromContext(ctx)
+	name, strategy, err := lookup(settings.Model)
+	if err != nil {
+		return game.WaitAction(agentID), err
+	}
+
+	action := strategy(actx)
+	action.AgentID = agentID
+	action.Reasoning = name + " bot"
+	action.ReceivedAt = time.Now()
+	return action, nil
+}
+
+// ValidateModel rejects unknown strategy names
+func (c *Client) ValidateModel(settings game.ModelSettings) error {
+	_, _, err := lookup(settings.Model)
+	return err
+}
+
+// lookup finds a strategy by name ("" = DefaultStrategy)
+func lookup(name string) (string, Strategy, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	strategy, ok := Strategies[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown bot %q (want %s)", name, strings.Join(Names(), ", "))
+	}
+	return name, strategy, nil
+}
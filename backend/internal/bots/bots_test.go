@@ -0,0 +1,226 @@
+package bots
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/game/actions"
+)
+
+// testPrompt builds the same prompt for every agent; bots ignore it
+type testPrompt struct{}
+
+func (testPrompt) BuildPrompt(game.AgentContext) string { return "prompt" }
+
+func TestStrategies(t *testing.T) {
+	me := game.NewAgent(uuid.New(), "Bot", "", game.Position{X: 5, Y: 5}, 5)
+	enemy := &game.AgentSnapshot{ID: uuid.New(), Position: game.Position{X: 6, Y: 6}, HP: 3}
+	owned := func(x, y int) *game.Tile {
+		return &game.Tile{Position: game.Position{X: x, Y: y}, OwnerID: &me.ID}
+	}
+
+	cases := []struct {
+		name     string
+		strategy Strategy
+		actx     game.AgentContext
+		want     game.ActionType
+	}{
+		{"expander claims unowned ground", Expander, game.AgentContext{Agent: me}, game.ActionClaim},
+		{"expander walks to unowned tiles", Expander, game.AgentContext{
+			Agent:            me,
+			CurrentTileOwned: true,
+			VisibleTiles:     []*game.Tile{owned(5, 5), {Position: game.Position{X: 7, Y: 5}}},
+		}, game.ActionMove},
+		{"raider attacks adjacent agents", Raider, game.AgentContext{
+			Agent:         me,
+			VisibleAgents: []*game.AgentSnapshot{enemy},
+		}, game.ActionFight},
+		{"raider captures enemy ground", Raider, game.AgentContext{Agent: me, CurrentTileEnemy: true}, game.ActionClaim},
+		{"harvester harvests underfoot", Harvester, game.AgentContext{
+			Agent:          me,
+			VisibleObjects: []*game.WorldObject{{Type: game.ObjectResource, Position: game.Position{X: 5, Y: 5}, Remaining: 3}},
+		}, game.ActionHarvest},
+		{"turtle stays home", Turtle, game.AgentContext{
+			Agent:            me,
+			CurrentTileOwned: true,
+			VisibleTiles:     []*game.Tile{owned(5, 5), {Position: game.Position{X: 9, Y: 9}}},
+		}, game.ActionWait},
+	}
+	for _, c := range cases {
+		got := c.strategy(c.actx)
+		if got.Type != c.want {
+			t.Errorf("%s: expected %s, got %+v", c.name, c.want, got)
+		}
+		if again := c.strategy(c.actx); again.Type != got.Type || !reflect.DeepEqual(again.Params, got.Params) {
+			t.Errorf("%s: expected the same action for the same context, got %+v then %+v", c.name, got, again)
+		}
+	}
+
+	if got := Expander(cases[1].actx); got.Params.Direction != game.DirEast {
+		t.Errorf("expected expander to head east, got %s", got.Params.Direction)
+	}
+	if got := Raider(cases[2].actx); got.Params.Target == nil || *got.Params.Target != enemy.ID {
+		t.Errorf("expected raider to target the adjacent agent, got %+v", got.Params.Target)
+	}
+}
+
+func TestHarvesterTrades(t *testing.T) {
+	me := game.NewAgent(uuid.New(), "Bot", "", game.Position{}, 5)
+	me.InitInventory(game.DefaultItemRegistry())
+	me.AddCoins(16)
+	actx := game.AgentContext{Agent: me, CurrentTileOwned: true}
+
+	if got := Harvester(actx); got.Type != game.ActionBuy || got.Params.ItemID != "armor" {
+		t.Errorf("expected harvester to buy the first affordable item, got %+v", got)
+	}
+	me.Inventory.AddItem("energy_potion", 1)
+	if got := Harvester(actx); got.Type != game.ActionUse || got.Params.ItemID != "energy_potion" {
+		t.Errorf("expected harvester to drink its energy potion, got %+v", got)
+	}
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient()
+	agentID := uuid.New()
+
+	if _, err := client.GetAction(context.Background(), agentID, "prompt"); err == nil {
+		t.Error("expected an error without the agent context")
+	}
+	if err := client.ValidateModel(game.ModelSettings{Provider: Provider, Model: "pacifist"}); err == nil {
+		t.Error("expected unknown bot to be rejected")
+	}
+
+	agent := game.NewAgent(uuid.New(), "Bot", "", game.Position{}, 5)
+	ctx := game.WithAgentContext(context.Background(), game.AgentContext{Agent: agent})
+	ctx = game.WithModelSettings(ctx, game.ModelSettings{Provider: Provider, Model: "raider"})
+	action, err := client.GetAction(ctx, agentID, "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.AgentID != agentID || action.Reasoning != "raider bot" {
+		t.Errorf("expected a raider action for the agent, got %+v", action)
+	}
+}
+
+func TestBotsPlayGame(t *testing.T) {
+	m, err := game.ParseAuthoredMap([]byte(
+		"1....W....2\n" +
+			"...........\n" +
+			"..R.....H..\n" +
+			"...........\n" +
+			"...........\n" +
+			".....K.....\n" +
+			"...........\n" +
+			"...........\n" +
+			"..W.....R..\n" +
+			"...........\n" +
+			"3.........4"))
+	if err != nil {
+		t.Fatalf("failed to parse map: %v", err)
+	}
+	cfg := config.Default().Game
+	cfg.MapSize = m.Size()
+	cfg.MaxPlayers = 4
+	engine := game.NewEngineWithAuthoredMap(uuid.New(), cfg, config.DefaultBalanceConfig(), m, NewClient(), testPrompt{}, nil, 1)
+	registry := game.NewHandlerRegistry()
+	actions.RegisterAllHandlers(registry)
+	engine.SetHandlerRegistry(registry)
+
+	for i, name := range Names() {
+		agent := game.NewAgent(engine.ID, name, "", m.SpawnPoints()[i], 5)
+		agent.Model = game.ModelSettings{Provider: Provider, Model: name}
+		if err := engine.AddAgent(agent); err != nil {
+			t.Fatalf("failed to add agent: %v", err)
+		}
+	}
+	engine.SetPaused(true)
+	if err := engine.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer engine.Stop()
+
+	for i := 0; i < 30; i++ {
+		engine.ForceTick()
+	}
+
+	for _, stats := range engine.Stats() {
+		if stats.LLMErrors != 0 {
+			t.Errorf("%s: expected no bot errors, got %d", stats.Name, stats.LLMErrors)
+		}
+		if stats.PeakTerritory == 0 {
+			t.Errorf("%s: expected the bot to claim territory", stats.Name)
+		}
+	}
+}
+
+func TestRouter_HeuristicFallback(t *testing.T) {
+	m, err := game.ParseAuthoredMap([]byte("1....\n.....\n.....\n.....\n....2"))
+	if err != nil {
+		t.Fatalf("failed to parse map: %v", err)
+	}
+	cfg := config.Default().Game
+	cfg.MapSize = m.Size()
+	down := llmFunc(func(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+		return game.WaitAction(agentID), errors.New("provider down")
+	})
+	engine := game.NewEngineWithAuthoredMap(uuid.New(), cfg, config.DefaultBalanceConfig(), m, NewRouter(down), testPrompt{}, nil, 1)
+	registry := game.NewHandlerRegistry()
+	actions.RegisterAllHandlers(registry)
+	engine.SetHandlerRegistry(registry)
+
+	agent := game.NewAgent(engine.ID, "Alice", "", m.SpawnPoints()[0], 5)
+	agent.Model = game.ModelSettings{Fallback: game.FallbackHeuristic}
+	if err := engine.AddAgent(agent); err != nil {
+		t.Fatalf("failed to add agent: %v", err)
+	}
+	engine.SetPaused(true)
+	if err := engine.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer engine.Stop()
+	engine.ForceTick()
+
+	stats := engine.Stats()[0]
+	if stats.Fallbacks[game.FallbackHeuristic] != 1 || stats.PeakTerritory == 0 {
+		t.Errorf("expected the expander bot to claim ground for the failed request, got %+v", stats)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var asked int
+	next := llmFunc(func(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+		asked++
+		return game.ClaimAction(agentID), nil
+	})
+	router := NewRouter(next)
+
+	agent := game.NewAgent(uuid.New(), "Bot", "", game.Position{}, 5)
+	ctx := game.WithAgentContext(context.Background(), game.AgentContext{Agent: agent})
+	bot := game.WithModelSettings(ctx, game.ModelSettings{Provider: Provider})
+	action, err := router.GetAction(bot, agent.ID, "prompt")
+	if err != nil || action.Reasoning != DefaultStrategy+" bot" {
+		t.Errorf("expected the default bot to play, got %+v, %v", action, err)
+	}
+	if _, err := router.GetAction(ctx, agent.ID, "prompt"); err != nil || asked != 1 {
+		t.Errorf("expected other agents to use the wrapped client, got %d requests, %v", asked, err)
+	}
+
+	if err := router.ValidateModel(game.ModelSettings{Provider: Provider}); err != nil {
+		t.Errorf("expected a bot without a model to use the default strategy, got %v", err)
+	}
+	if err := router.ValidateModel(game.ModelSettings{Provider: Provider, Model: "pacifist"}); err == nil {
+		t.Error("expected unknown bot to be rejected")
+	}
+}
+
+// llmFunc adapts a function to game.LLMClient
+type llmFunc func(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error)
+
+func (f llmFunc) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	return f(ctx, agentID, prompt)
+}
@@ -0,0 +1,109 @@
+package bots
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// distance is the number of single-tile moves between two positions
+func distance(a, b game.Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+// nearest returns the candidate closest to from, other than from itself.
+// Ties go to the top-most, then left-most position so bots stay deterministic.
+func nearest(from game.Position, candidates []game.Position) (game.Position, bool) {
+	var best game.Position
+	bestDist := -1
+	for _, pos := range candidates {
+		d := distance(from, pos)
+		if d == 0 {
+			continue
+		}
+		if bestDist < 0 || d < bestDist || (d == bestDist && (pos.Y < best.Y || (pos.Y == best.Y && pos.X < best.X))) {
+			best, bestDist = pos, d
+		}
+	}
+	return best, bestDist > 0
+}
+
+// toward returns the direction that closes the larger gap between from and to
+func toward(from, to game.Position) game.Direction {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	switch {
+	case abs(dx) >= abs(dy) && dx > 0:
+		return game.DirEast
+	case abs(dx) >= abs(dy) && dx < 0:
+		return game.DirWest
+	case dy > 0:
+		return game.DirSouth
+	default:
+		return game.DirNorth
+	}
+}
+
+// unownedTiles returns the visible tiles the agent doesn't own
+func unownedTiles(actx game.AgentContext) []game.Position {
+	var out []game.Position
+	for _, tile := range actx.VisibleTiles {
+		if tile.OwnerID == nil || *tile.OwnerID != actx.Agent.ID {
+			out = append(out, tile.Position)
+		}
+	}
+	return out
+}
+
+// enemyTiles returns the visible tiles owned by other agents
+func enemyTiles(actx game.AgentContext) []game.Position {
+	var out []game.Position
+	for _, tile := range actx.VisibleTiles {
+		if tile.OwnerID != nil && *tile.OwnerID != actx.Agent.ID {
+			out = append(out, tile.Position)
+		}
+	}
+	return out
+}
+
+// enemyPositions returns where the visible living agents stand
+func enemyPositions(actx game.AgentContext) []game.Position {
+	var out []game.Position
+	for _, other := range actx.VisibleAgents {
+		if other.ID != actx.Agent.ID && !other.IsDead {
+			out = append(out, other.Position)
+		}
+	}
+	return out
+}
+
+// adjacentEnemy returns a living agent within fighting range, preferring
+// the weakest, then the lowest ID
+func adjacentEnemy(actx game.AgentContext) (uuid.UUID, bool) {
+	here := actx.Agent.GetPosition()
+	var targets []*game.AgentSnapshot
+	for _, other := range actx.VisibleAgents {
+		dx, dy := abs(other.Position.X-here.X), abs(other.Position.Y-here.Y)
+		if other.ID != actx.Agent.ID && !other.IsDead && dx <= 1 && dy <= 1 && dx+dy > 0 {
+			targets = append(targets, other)
+		}
+	}
+	if len(targets) == 0 {
+		return uuid.Nil, false
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].HP != targets[j].HP {
+			return targets[i].HP < targets[j].HP
+		}
+		return targets[i].ID.String() < targets[j].ID.String()
+	})
+	return targets[0].ID, true
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package bots
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Router sends requests from agents on the bot provider to the bots and
+// everything else to the wrapped client, so bots play with any LLM
+// backend, including the mock client and replayed cassettes
+type Router struct {
+	bots *Client
+	next game.LLMClient
+}
+
+// NewRouter wraps an LLM client so agents can also be bots
+func NewRouter(next game.LLMClient) *Router {
+	return &Router{bots: NewClient(), next: next}
+}
+
+// GetAction runs the agent's bot or asks the wrapped client
+func (r *Router) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	if settings, _ := game.ModelSettingsFromContext(ctx); settings.Provider == Provider {
+		return r.bots.GetAction(ctx, agentID, prompt)
+	}
+	return r.next.GetAction(ctx, agentID, prompt)
+}
+
+// GenerateText defers to the wrapped client
+func (r *Router) GenerateText(ctx context.Context, prompt string) (string, error) {
+	gen, ok := r.next.(game.TextGenerator)
+	if !ok {
+		return "", fmt.Errorf("LLM client can't generate text")
+	}
+	return gen.GenerateText(ctx, prompt)
+}
+
+// ValidateModel checks bot strategy names and defers other models to the
+// wrapped client
+func (r *Router) ValidateModel(settings game.ModelSettings) error {
+	if settings.Provider == Provider {
+		return r.bots.ValidateModel(settings)
+	}
+	if validator, ok := r.next.(game.ModelValidator); ok {
+		return validator.ValidateModel(settings)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package bots
+
+import (
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// turtleRange is how far a turtle will walk to claim new ground
+const turtleRange = 3
+
+// shoppingList is what a harvester buys with its coins, in order
+var shoppingList = []string{"scout_ring", "armor", "sword", "energy_potion"}
+
+// items prices the shopping list
+var items = game.DefaultItemRegistry()
+
+// Expander claims the ground it stands on, then walks to the nearest
+// visible tile it doesn't own. It spends spare energy on claim radius and
+// speed.
+func Expander(actx game.AgentContext) game.Action {
+	id := actx.Agent.ID
+	if !actx.CurrentTileOwned {
+		return game.ClaimAction(id)
+	}
+	if action, ok := upgrade(actx, "claim", "speed"); ok {
+		return action
+	}
+	here := actx.Agent.GetPosition()
+	if target, ok := nearest(here, unownedTiles(actx)); ok {
+		return game.MoveAction(id, toward(here, target))
+	}
+	return explore(actx)
+}
+
+// Turtle holds a compact territory: it defends against adjacent agents,
+// only claims ground close by and invests in strength and vision
+func Turtle(actx game.AgentContext) game.Action {
+	id := actx.Agent.ID
+	if enemy, ok := adjacentEnemy(actx); ok {
+		return game.FightAction(id, enemy)
+	}
+	if !actx.CurrentTileOwned {
+		return game.ClaimAction(id)
+	}
+	if action, ok := upgrade(actx, "strength", "vision", "claim"); ok {
+		return action
+	}
+	here := actx.Agent.GetPosition()
+	if target, ok := nearest(here, unownedTiles(actx)); ok && distance(here, target) <= turtleRange {
+		return game.MoveAction(id, toward(here, target))
+	}
+	return game.WaitAction(id)
+}
+
+// Raider hunts other agents and captures their territory, falling back to
+// expanding when nobody is in sight
+func Raider(actx game.AgentContext) game.Action {
+	id := actx.Agent.ID
+	if enemy, ok := adjacentEnemy(actx); ok {
+		return game.FightAction(id, enemy)
+	}
+	if actx.CurrentTileEnemy {
+		return game.ClaimAction(id)
+	}
+	if action, ok := upgrade(actx, "strength"); ok {
+		return action
+	}
+	here := actx.Agent.GetPosition()
+	if target, ok := nearest(here, enemyPositions(actx)); ok {
+		return game.MoveAction(id, toward(here, target))
+	}
+	if target, ok := nearest(here, enemyTiles(actx)); ok {
+		return game.MoveAction(id, toward(here, target))
+	}
+	return Expander(actx)
+}
+
+// Harvester gathers resources and dropped items, claiming along the way,
+// trades its coins for equipment and potions and expands when nothing is
+// in sight
+func Harvester(actx game.AgentContext) game.Action {
+	id := actx.Agent.ID
+	here := actx.Agent.GetPosition()
+
+	var targets []game.Position
+	for _, obj := range actx.VisibleObjects {
+		switch obj.Type {
+		case game.ObjectResource:
+			if obj.Remaining <= 0 {
+				continue
+			}
+			if obj.Position == here {
+				return game.HarvestAction(id)
+			}
+		case game.ObjectDroppedItem:
+			if obj.Position == here {
+				return game.PickupAction(id)
+			}
+		default:
+			continue
+		}
+		targets = append(targets, obj.Position)
+	}
+
+	if !actx.CurrentTileOwned {
+		return game.ClaimAction(id)
+	}
+	if action, ok := trade(actx); ok {
+		return action
+	}
+	if action, ok := upgrade(actx, "storage", "speed"); ok {
+		return action
+	}
+	if target, ok := nearest(here, targets); ok {
+		return game.MoveAction(id, toward(here, target))
+	}
+	return Expander(actx)
+}
+
+// upgrade returns an UPGRADE for the first affordable type, in order
+func upgrade(actx game.AgentContext, types ...string) (game.Action, bool) {
+	for _, upgradeType := range types {
+		if ok, _, _ := actx.Agent.CanUpgrade(upgradeType); ok {
+			return game.UpgradeAction(actx.Agent.ID, upgradeType), true
+		}
+	}
+	return game.Action{}, false
+}
+
+// trade buys the first affordable item on the shopping list the agent
+// doesn't carry yet, or drinks an energy potion when it has one
+func trade(actx game.AgentContext) (game.Action, bool) {
+	inv := actx.Agent.Inventory
+	if inv == nil {
+		return game.Action{}, false
+	}
+	if inv.HasItems("energy_potion", 1) {
+		return game.UseAction(actx.Agent.ID, "energy_potion"), true
+	}
+	if inv.IsFull() {
+		return game.Action{}, false
+	}
+	coins := actx.Agent.GetCoins()
+	for _, itemID := range shoppingList {
+		def := items.Get(itemID)
+		if def == nil || inv.HasItems(itemID, 1) {
+			continue
+		}
+		if cost := def.GetPropertyInt("coin_cost", 0); cost > 0 && coins >= cost {
+			return game.BuyAction(actx.Agent.ID, itemID), true
+		}
+	}
+	return game.Action{}, false
+}
+
+// explore heads for the map center, then circles around it, changing
+// direction every few ticks
+func explore(actx game.AgentContext) game.Action {
+	here := actx.Agent.GetPosition()
+	center := game.Position{X: actx.WorldSize / 2, Y: actx.WorldSize / 2}
+	if distance(here, center) > actx.WorldSize/4 {
+		return game.MoveAction(actx.Agent.ID, toward(here, center))
+	}
+	dirs := []game.Direction{game.DirNorth, game.DirEast, game.DirSouth, game.DirWest}
+	return game.MoveAction(actx.Agent.ID, dirs[(actx.CurrentTick/4)%len(dirs)])
+}
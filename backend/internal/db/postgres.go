@@ -2,7 +2,7 @@ package db
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -29,7 +29,7 @@ func NewPostgres(connString string) (*Postgres, error) {
 		return nil, err
 	}
 
-	log.Println("Connected to PostgreSQL")
+	slog.Info("Connected to PostgreSQL")
 	return &Postgres{pool: pool}, nil
 }
 
@@ -2,7 +2,7 @@ package db
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -33,7 +33,7 @@ func NewRedis(addr string) (*Redis, error) {
 		return nil, err
 	}
 
-	log.Println("Connected to Redis")
+	slog.Info("Connected to Redis")
 	return &Redis{client: client}, nil
 }
 
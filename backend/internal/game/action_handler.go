@@ -1,6 +1,8 @@
 package game
 
 import (
+	"sort"
+
 	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/config"
 )
@@ -85,6 +87,16 @@ func (r *HandlerRegistry) Has(t ActionType) bool {
 	return ok
 }
 
+// Types returns the registered action types in sorted order
+func (r *HandlerRegistry) Types() []ActionType {
+	types := make([]ActionType, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
 // FailedResult creates a failed action result with the given message
 func FailedResult(agentID uuid.UUID, actionType ActionType, message string) ActionResult {
 	return ActionResult{
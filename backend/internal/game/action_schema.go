@@ -0,0 +1,82 @@
+package game
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ActionSchema returns a JSON Schema for the flat action object ParseAction
+// reads: an "action" enum of the given types plus one property per
+// ActionParams field and "reasoning". Providers that support structured
+// output send it as a tool declaration or response schema.
+func ActionSchema(types []ActionType) map[string]interface{} {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+
+	properties := map[string]interface{}{
+		"action": map[string]interface{}{
+			"type":        "string",
+			"enum":        names,
+			"description": "The action to take this tick",
+		},
+	}
+
+	uuidType := reflect.TypeOf(uuid.UUID{})
+	params := reflect.TypeOf(ActionParams{})
+	for i := 0; i < params.NumField(); i++ {
+		field := params.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		prop := map[string]interface{}{}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType == uuidType:
+			prop["type"] = "string"
+			prop["format"] = "uuid"
+		case fieldType.Kind() == reflect.Int:
+			prop["type"] = "integer"
+		default:
+			prop["type"] = "string"
+		}
+		if desc := field.Tag.Get("desc"); desc != "" {
+			prop["description"] = desc
+		}
+		properties[name] = prop
+	}
+
+	properties["reasoning"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Brief explanation of the choice",
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"action"},
+	}
+}
+
+type actionSchemaKey struct{}
+
+// WithActionSchema attaches the game's action schema to an LLM request
+// context. Clients without structured output support ignore it.
+func WithActionSchema(ctx context.Context, schema map[string]interface{}) context.Context {
+	return context.WithValue(ctx, actionSchemaKey{}, schema)
+}
+
+// ActionSchemaFromContext returns the action schema attached to ctx, if any
+func ActionSchemaFromContext(ctx context.Context) (map[string]interface{}, bool) {
+	schema, ok := ctx.Value(actionSchemaKey{}).(map[string]interface{})
+	return schema, ok && schema != nil
+}
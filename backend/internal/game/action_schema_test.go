@@ -0,0 +1,33 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestActionSchema(t *testing.T) {
+	schema := ActionSchema([]ActionType{ActionClaim, ActionMove})
+	props := schema["properties"].(map[string]interface{})
+
+	action := props["action"].(map[string]interface{})
+	if enum := action["enum"].([]string); len(enum) != 2 || enum[0] != "CLAIM" || enum[1] != "MOVE" {
+		t.Errorf("expected action enum of the given types, got %v", action["enum"])
+	}
+	for name, wantType := range map[string]string{"direction": "string", "steps": "integer", "target": "string", "upgrade_type": "string", "reasoning": "string"} {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok || prop["type"] != wantType {
+			t.Errorf("expected %s to be a %s property, got %v", name, wantType, props[name])
+		}
+	}
+	if props["target"].(map[string]interface{})["format"] != "uuid" {
+		t.Error("expected target to be a uuid")
+	}
+
+	// Actions matching the schema parse
+	data, _ := json.Marshal(map[string]interface{}{"action": "MOVE", "direction": "west", "steps": 1})
+	if parsed, err := ParseAction(uuid.New(), data); err != nil || parsed.Params.Direction != DirWest {
+		t.Errorf("expected schema-shaped action to parse, got %+v, %v", parsed, err)
+	}
+}
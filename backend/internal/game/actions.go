@@ -3,6 +3,8 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,21 +41,24 @@ const (
 
 // Action represents an agent's action for a tick
 type Action struct {
-	Type       ActionType   `json:"action"`
-	AgentID    uuid.UUID    `json:"agent_id"`
-	Params     ActionParams `json:"params,omitempty"`
-	Reasoning  string       `json:"reasoning,omitempty"`
-	ReceivedAt time.Time    `json:"-"`
+	Type       ActionType     `json:"action"`
+	AgentID    uuid.UUID      `json:"agent_id"`
+	Params     ActionParams   `json:"params,omitempty"`
+	Reasoning  string         `json:"reasoning,omitempty"`
+	ReceivedAt time.Time      `json:"-"`
+	Raw        string         `json:"-"`                  // Unparsed LLM response, kept for dataset export
+	Fallback   FallbackPolicy `json:"fallback,omitempty"` // Set when the LLM failed and a fallback policy chose this action
+	Repairs    []string       `json:"repairs,omitempty"`  // Fixes applied to parse a malformed response, see ParseAction
 }
 
 // ActionParams holds the parameters for different action types
 type ActionParams struct {
-	Direction   Direction  `json:"direction,omitempty"`    // For MOVE
-	Steps       int        `json:"steps,omitempty"`        // For MOVE: number of steps (0 = use max)
-	Target      *uuid.UUID `json:"target,omitempty"`       // For MESSAGE (nil = broadcast), FIGHT
-	Message     string     `json:"message,omitempty"`      // For MESSAGE
-	ItemID      string     `json:"item_id,omitempty"`      // For USE
-	UpgradeType string     `json:"upgrade_type,omitempty"` // For UPGRADE: vision, memory, strength, storage, speed, claim
+	Direction   Direction  `json:"direction,omitempty" desc:"MOVE: north, south, east or west"`
+	Steps       int        `json:"steps,omitempty" desc:"MOVE: number of steps (0 = as far as possible)"`
+	Target      *uuid.UUID `json:"target,omitempty" desc:"FIGHT: agent ID to attack; MESSAGE: recipient agent ID (omit to broadcast)"`
+	Message     string     `json:"message,omitempty" desc:"MESSAGE: text to send"`
+	ItemID      string     `json:"item_id,omitempty" desc:"USE or BUY: item ID"`
+	UpgradeType string     `json:"upgrade_type,omitempty" desc:"UPGRADE: vision, memory, strength, storage, speed or claim"`
 }
 
 // WaitAction returns a default wait action
@@ -143,17 +148,92 @@ func BuyAction(agentID uuid.UUID, itemID string) Action {
 	}
 }
 
-// ParseAction parses a JSON action from LLM response
+// Repairs applied by ParseAction to loosely formatted actions, recorded in
+// Action.Repairs
+const (
+	RepairActionCase    = "action_case"    // Action name in the wrong case or with spaces/dashes
+	RepairActionAlias   = "action_alias"   // Synonym or removed action, e.g. ATTACK or HOLD
+	RepairDirection     = "direction"      // Direction synonym, e.g. "N", "up" or "North"
+	RepairNestedParams  = "nested_params"  // Parameters inside a "params" object
+	RepairNumericString = "numeric_string" // Number sent as a string, e.g. "steps": "2"
+)
+
+// actionAliases maps synonyms and removed actions to current action types
+var actionAliases = map[string]ActionType{
+	"HOLD":         ActionWait,
+	"IDLE":         ActionWait,
+	"REST":         ActionWait,
+	"NONE":         ActionWait,
+	"PLACE":        ActionUse,
+	"BUILD":        ActionUse,
+	"GO":           ActionMove,
+	"WALK":         ActionMove,
+	"ATTACK":       ActionFight,
+	"CAPTURE":      ActionClaim,
+	"GATHER":       ActionHarvest,
+	"COLLECT":      ActionHarvest,
+	"MINE":         ActionHarvest,
+	"PICK_UP":      ActionPickup,
+	"TAKE":         ActionPickup,
+	"SAY":          ActionMessage,
+	"SEND_MESSAGE": ActionMessage,
+	"BROADCAST":    ActionMessage,
+	"PURCHASE":     ActionBuy,
+}
+
+// directionAliases maps lower-cased direction synonyms to directions
+var directionAliases = map[string]Direction{
+	"n": DirNorth, "up": DirNorth,
+	"s": DirSouth, "down": DirSouth,
+	"e": DirEast, "right": DirEast,
+	"w": DirWest, "left": DirWest,
+}
+
+// rawActionParams holds the parameters of a flat or nested action object
+type rawActionParams struct {
+	Direction   string     `json:"direction,omitempty"`
+	Steps       lenientInt `json:"steps,omitempty"`
+	Target      string     `json:"target,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	ItemID      string     `json:"item_id,omitempty"`
+	UpgradeType string     `json:"upgrade_type,omitempty"`
+}
+
+// lenientInt decodes a JSON number or a numeric string, noting the latter
+type lenientInt struct {
+	value  int
+	quoted bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *lenientInt) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		n.value = int(f)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("steps must be a number")
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("steps must be a number")
+	}
+	n.value, n.quoted = v, true
+	return nil
+}
+
+// ParseAction parses a JSON action from LLM response. It tolerates common
+// slips, such as lower-case or synonym action names, "N" or "up" for
+// north, and parameters nested under "params", and lists the fixes it
+// applied in Action.Repairs.
 func ParseAction(agentID uuid.UUID, data []byte) (Action, error) {
 	var raw struct {
-		Action      string `json:"action"`
-		Direction   string `json:"direction,omitempty"`
-		Steps       int    `json:"steps,omitempty"`
-		Target      string `json:"target,omitempty"`
-		Message     string `json:"message,omitempty"`
-		ItemID      string `json:"item_id,omitempty"`
-		UpgradeType string `json:"upgrade_type,omitempty"`
-		Reasoning   string `json:"reasoning,omitempty"`
+		Action    string           `json:"action"`
+		Reasoning string           `json:"reasoning,omitempty"`
+		Params    *rawActionParams `json:"params,omitempty"`
+		rawActionParams
 	}
 
 	if err := json.Unmarshal(data, &raw); err != nil {
@@ -165,34 +245,55 @@ func ParseAction(agentID uuid.UUID, data []byte) (Action, error) {
 		Reasoning:  raw.Reasoning,
 		ReceivedAt: time.Now(),
 	}
+	params := raw.rawActionParams
+	if raw.Params != nil {
+		params = mergeParams(params, *raw.Params)
+		action.Repairs = append(action.Repairs, RepairNestedParams)
+	}
+	if params.Steps.quoted {
+		action.Repairs = append(action.Repairs, RepairNumericString)
+	}
 
-	// Backwards compatibility: map removed actions to their replacements
-	actionType := ActionType(raw.Action)
-	switch actionType {
-	case "HOLD":
-		actionType = ActionWait
-	case "PLACE":
-		actionType = ActionUse
+	actionType, arg, repairs := normalizeActionType(raw.Action)
+	action.Repairs = append(action.Repairs, repairs...)
+	if arg != "" {
+		switch actionType { // e.g. "MOVE north" or "UPGRADE vision"
+		case ActionMove:
+			if params.Direction == "" {
+				params.Direction = arg
+			}
+		case ActionUpgrade:
+			if params.UpgradeType == "" {
+				params.UpgradeType = arg
+			}
+		case ActionUse, ActionBuy:
+			if params.ItemID == "" {
+				params.ItemID = arg
+			}
+		}
 	}
 
 	switch actionType {
 	case ActionMove:
 		action.Type = ActionMove
-		dir := Direction(raw.Direction)
-		if !isValidDirection(dir) {
-			return WaitAction(agentID), fmt.Errorf("invalid direction: %s", raw.Direction)
+		dir, ok, repaired := normalizeDirection(params.Direction)
+		if !ok {
+			return WaitAction(agentID), fmt.Errorf("invalid direction: %s", params.Direction)
+		}
+		if repaired {
+			action.Repairs = append(action.Repairs, RepairDirection)
 		}
 		action.Params.Direction = dir
-		action.Params.Steps = raw.Steps
+		action.Params.Steps = params.Steps.value
 
 	case ActionClaim:
 		action.Type = ActionClaim
 
 	case ActionMessage:
 		action.Type = ActionMessage
-		action.Params.Message = raw.Message
-		if raw.Target != "" {
-			targetID, err := uuid.Parse(raw.Target)
+		action.Params.Message = params.Message
+		if params.Target != "" {
+			targetID, err := uuid.Parse(params.Target)
 			if err != nil {
 				// Invalid target, send as broadcast
 				action.Params.Target = nil
@@ -206,12 +307,12 @@ func ParseAction(agentID uuid.UUID, data []byte) (Action, error) {
 
 	case ActionFight:
 		action.Type = ActionFight
-		if raw.Target == "" {
+		if params.Target == "" {
 			return WaitAction(agentID), fmt.Errorf("FIGHT requires target")
 		}
-		targetID, err := uuid.Parse(raw.Target)
+		targetID, err := uuid.Parse(params.Target)
 		if err != nil {
-			return WaitAction(agentID), fmt.Errorf("invalid target ID: %s", raw.Target)
+			return WaitAction(agentID), fmt.Errorf("invalid target ID: %s", params.Target)
 		}
 		action.Params.Target = &targetID
 
@@ -220,27 +321,27 @@ func ParseAction(agentID uuid.UUID, data []byte) (Action, error) {
 
 	case ActionUse:
 		action.Type = ActionUse
-		if raw.ItemID == "" {
+		if params.ItemID == "" {
 			return WaitAction(agentID), fmt.Errorf("USE requires item_id")
 		}
-		action.Params.ItemID = raw.ItemID
+		action.Params.ItemID = params.ItemID
 
 	case ActionHarvest:
 		action.Type = ActionHarvest
 
 	case ActionUpgrade:
 		action.Type = ActionUpgrade
-		if raw.UpgradeType == "" {
+		if params.UpgradeType == "" {
 			return WaitAction(agentID), fmt.Errorf("UPGRADE requires upgrade_type")
 		}
-		action.Params.UpgradeType = raw.UpgradeType
+		action.Params.UpgradeType = strings.ToLower(strings.TrimSpace(params.UpgradeType))
 
 	case ActionBuy:
 		action.Type = ActionBuy
-		if raw.ItemID == "" {
+		if params.ItemID == "" {
 			return WaitAction(agentID), fmt.Errorf("BUY requires item_id")
 		}
-		action.Params.ItemID = raw.ItemID
+		action.Params.ItemID = params.ItemID
 
 	default:
 		return WaitAction(agentID), fmt.Errorf("unknown action: %s", raw.Action)
@@ -249,6 +350,66 @@ func ParseAction(agentID uuid.UUID, data []byte) (Action, error) {
 	return action, nil
 }
 
+// mergeParams fills empty top-level parameters from a nested params object
+func mergeParams(top, nested rawActionParams) rawActionParams {
+	if top.Direction == "" {
+		top.Direction = nested.Direction
+	}
+	if top.Steps.value == 0 {
+		top.Steps = nested.Steps
+	}
+	if top.Target == "" {
+		top.Target = nested.Target
+	}
+	if top.Message == "" {
+		top.Message = nested.Message
+	}
+	if top.ItemID == "" {
+		top.ItemID = nested.ItemID
+	}
+	if top.UpgradeType == "" {
+		top.UpgradeType = nested.UpgradeType
+	}
+	return top
+}
+
+// normalizeActionType maps a loosely written action name to an action
+// type. When the first of two words is an action, as in "MOVE north", the
+// second is returned as arg.
+func normalizeActionType(name string) (actionType ActionType, arg string, repairs []string) {
+	trimmed := strings.TrimSpace(name)
+	if fields := strings.Fields(trimmed); len(fields) == 2 {
+		if t, _, _ := normalizeActionType(fields[0]); t != "" {
+			trimmed, arg = fields[0], fields[1]
+		}
+	}
+
+	key := strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(trimmed))
+	if key != trimmed && key != "" {
+		repairs = append(repairs, RepairActionCase)
+	}
+	if alias, ok := actionAliases[key]; ok {
+		return alias, arg, append(repairs, RepairActionAlias)
+	}
+	switch t := ActionType(key); t {
+	case ActionMove, ActionClaim, ActionMessage, ActionWait, ActionFight,
+		ActionPickup, ActionUse, ActionHarvest, ActionUpgrade, ActionBuy:
+		return t, arg, repairs
+	}
+	return "", arg, nil
+}
+
+// normalizeDirection maps a loosely written direction to a direction,
+// reporting whether it had to be repaired
+func normalizeDirection(s string) (dir Direction, ok, repaired bool) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if alias, found := directionAliases[key]; found {
+		return alias, true, true
+	}
+	dir = Direction(key)
+	return dir, isValidDirection(dir), key != s
+}
+
 // isValidDirection checks if a direction is valid
 func isValidDirection(dir Direction) bool {
 	switch dir {
@@ -277,21 +438,25 @@ func GetNewPosition(current Position, dir Direction) Position {
 
 // ActionResult represents the outcome of applying an action
 type ActionResult struct {
-	AgentID      uuid.UUID  `json:"agent_id"`
-	Action       ActionType `json:"action"`
-	Success      bool       `json:"success"`
-	Message      string     `json:"message,omitempty"`
-	Reasoning    string     `json:"reasoning,omitempty"`
-	OldPos       *Position  `json:"old_pos,omitempty"`
-	NewPos       *Position  `json:"new_pos,omitempty"`
-	ClaimedAt    *Position  `json:"claimed_at,omitempty"`
-	ClaimedTiles []Position `json:"-"`                       // All tiles claimed (not serialized to client)
-	TargetID     *uuid.UUID `json:"target_id,omitempty"`     // For FIGHT
-	DamageDealt  int        `json:"damage_dealt,omitempty"`  // For FIGHT
-	ItemID       string     `json:"item_id,omitempty"`       // For item-related actions
-	ItemQuantity int        `json:"item_quantity,omitempty"` // For HARVEST, PICKUP
-	Harvested    string     `json:"harvested,omitempty"`     // Resource type harvested
-	Placed       string     `json:"placed,omitempty"`        // Structure placed
-	Upgraded     string     `json:"upgraded,omitempty"`      // Upgrade type
-	NewLevel     int        `json:"new_level,omitempty"`     // New upgrade level
+	AgentID      uuid.UUID         `json:"agent_id"`
+	Action       ActionType        `json:"action"`
+	Success      bool              `json:"success"`
+	Message      string            `json:"message,omitempty"`
+	Reasoning    string            `json:"reasoning,omitempty"`
+	OldPos       *Position         `json:"old_pos,omitempty"`
+	NewPos       *Position         `json:"new_pos,omitempty"`
+	ClaimedAt    *Position         `json:"claimed_at,omitempty"`
+	ClaimedTiles []Position        `json:"-"`                       // All tiles claimed (not serialized to client)
+	ClearedTiles []Position        `json:"-"`                       // Tiles released when an agent died (not serialized to client)
+	CapturedFrom map[uuid.UUID]int `json:"-"`                       // Tiles taken from each other agent by a CLAIM
+	Killed       *uuid.UUID        `json:"-"`                       // Agent that died as a result of this action
+	TargetID     *uuid.UUID        `json:"target_id,omitempty"`     // For FIGHT
+	DamageDealt  int               `json:"damage_dealt,omitempty"`  // For FIGHT
+	ItemID       string            `json:"item_id,omitempty"`       // For item-related actions
+	ItemQuantity int               `json:"item_quantity,omitempty"` // For HARVEST, PICKUP
+	Harvested    string            `json:"harvested,omitempty"`     // Resource type harvested
+	Placed       string            `json:"placed,omitempty"`        // Structure placed
+	Upgraded     string            `json:"upgraded,omitempty"`      // Upgrade type
+	NewLevel     int               `json:"new_level,omitempty"`     // New upgrade level
+	Fallback     FallbackPolicy    `json:"fallback,omitempty"`      // Fallback policy that chose the action, if the LLM failed
 }
@@ -3,6 +3,7 @@ package actions
 import (
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/game"
 )
 
@@ -53,6 +54,7 @@ func (h *ClaimHandler) Process(ctx *ActionContext) game.ActionResult {
 	claimedCount := 0
 	capturedCount := 0
 	var claimedTiles []game.Position
+	capturedFrom := make(map[uuid.UUID]int)
 
 	for dy := -radius; dy <= radius; dy++ {
 		for dx := -radius; dx <= radius; dx++ {
@@ -76,6 +78,9 @@ func (h *ClaimHandler) Process(ctx *ActionContext) game.ActionResult {
 			}
 
 			wasEnemy := tile.OwnerID != nil
+			if wasEnemy {
+				capturedFrom[*tile.OwnerID]++
+			}
 			ctx.World.SetOwner(tilePos, &ctx.Agent.ID)
 			claimedTiles = append(claimedTiles, tilePos)
 
@@ -97,6 +102,7 @@ func (h *ClaimHandler) Process(ctx *ActionContext) game.ActionResult {
 	result.Success = true
 	result.ClaimedTiles = claimedTiles
 	if capturedCount > 0 {
+		result.CapturedFrom = capturedFrom
 		result.Message = fmt.Sprintf("claimed %d tiles (%d captured from enemies)", total, capturedCount)
 	} else {
 		result.Message = fmt.Sprintf("claimed %d tiles", total)
@@ -106,6 +106,8 @@ func (h *FightHandler) Process(ctx *ActionContext) game.ActionResult {
 		for _, pos := range ownedTiles {
 			ctx.World.SetOwner(pos, nil)
 		}
+		result.ClearedTiles = ownedTiles
+		result.Killed = &targetID
 
 		// Clear inventory
 		if target.Inventory != nil {
@@ -287,6 +287,12 @@ func TestFightHandler_KillsTarget(t *testing.T) {
 	if len(ownedTilesAfter) != 0 {
 		t.Errorf("expected target to own 0 tiles after death, got %d", len(ownedTilesAfter))
 	}
+	if len(result.ClearedTiles) != 2 {
+		t.Errorf("expected result to report 2 cleared tiles, got %d", len(result.ClearedTiles))
+	}
+	if result.Killed == nil || *result.Killed != target.ID {
+		t.Errorf("expected result to report the target as killed, got %v", result.Killed)
+	}
 
 	// Verify inventory is cleared
 	woodAfter := target.Inventory.GetItemCount("wood")
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAction_Lenient(t *testing.T) {
+	agentID := uuid.New()
+	cases := []struct {
+		input   string
+		want    ActionType
+		dir     Direction
+		steps   int
+		repairs []string
+	}{
+		{input: `{"action": "MOVE", "direction": "north"}`, want: ActionMove, dir: DirNorth},
+		{input: `{"action": "move", "direction": "North"}`, want: ActionMove, dir: DirNorth, repairs: []string{RepairActionCase, RepairDirection}},
+		{input: `{"action": "MOVE", "direction": "N"}`, want: ActionMove, dir: DirNorth, repairs: []string{RepairDirection}},
+		{input: `{"action": "MOVE", "direction": "up", "steps": "2"}`, want: ActionMove, dir: DirNorth, steps: 2, repairs: []string{RepairNumericString, RepairDirection}},
+		{input: `{"action": "MOVE west"}`, want: ActionMove, dir: DirWest},
+		{input: `{"action": "MOVE north"}`, want: ActionMove, dir: DirNorth},
+		{input: `{"action": "move north"}`, want: ActionMove, dir: DirNorth, repairs: []string{RepairActionCase}},
+		{input: `{"action": "MOVE", "params": {"direction": "east", "steps": 3}}`, want: ActionMove, dir: DirEast, steps: 3, repairs: []string{RepairNestedParams}},
+		{input: `{"action": "hold"}`, want: ActionWait, repairs: []string{RepairActionCase, RepairActionAlias}},
+		{input: `{"action": "pick-up"}`, want: ActionPickup, repairs: []string{RepairActionCase, RepairActionAlias}},
+		{input: `{"action": "Gather"}`, want: ActionHarvest, repairs: []string{RepairActionCase, RepairActionAlias}},
+	}
+
+	for _, c := range cases {
+		action, err := ParseAction(agentID, []byte(c.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.input, err)
+			continue
+		}
+		if action.Type != c.want || action.Params.Direction != c.dir || action.Params.Steps != c.steps {
+			t.Errorf("%s: got %s %s %d, want %s %s %d", c.input,
+				action.Type, action.Params.Direction, action.Params.Steps, c.want, c.dir, c.steps)
+		}
+		if !reflect.DeepEqual(action.Repairs, c.repairs) {
+			t.Errorf("%s: expected repairs %v, got %v", c.input, c.repairs, action.Repairs)
+		}
+	}
+}
+
+func TestParseAction_Invalid(t *testing.T) {
+	agentID := uuid.New()
+	for _, input := range []string{
+		`{"action": "MOVE", "direction": "northeast"}`,
+		`{"action": "DANCE"}`,
+		`{"action": "MOVE", "steps": "far"}`,
+		`not json`,
+	} {
+		action, err := ParseAction(agentID, []byte(input))
+		if err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+		if action.Type != ActionWait || action.AgentID != agentID {
+			t.Errorf("%s: expected WAIT fallback for the agent, got %+v", input, action)
+		}
+	}
+}
+
+func FuzzParseAction(f *testing.F) {
+	for _, seed := range []string{
+		`{"action": "MOVE", "direction": "north", "steps": 2}`,
+		`{"action": "move up"}`,
+		`{"action": "FIGHT", "target": "not-a-uuid"}`,
+		`{"action": "UPGRADE", "params": {"upgrade_type": "Vision"}}`,
+		`{"action": "MESSAGE", "message": "hi", "steps": "1e3"}`,
+		`{}`,
+	} {
+		f.Add(seed)
+	}
+	agentID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, input string) {
+		action, err := ParseAction(agentID, []byte(input))
+		if action.AgentID != agentID {
+			t.Fatalf("action for wrong agent: %+v", action)
+		}
+		if err != nil {
+			if action.Type != ActionWait {
+				t.Fatalf("expected WAIT on error, got %s", action.Type)
+			}
+			return
+		}
+		if action.Type == ActionMove && !isValidDirection(action.Params.Direction) {
+			t.Fatalf("MOVE with invalid direction %q", action.Params.Direction)
+		}
+	})
+}
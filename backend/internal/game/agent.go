@@ -10,17 +10,18 @@ import (
 
 // Agent represents a player's AI agent in the game
 type Agent struct {
-	mu           sync.RWMutex
-	ID           uuid.UUID  `json:"id"`
-	GameID       uuid.UUID  `json:"game_id"`
-	PlayerID     *uuid.UUID `json:"player_id,omitempty"`
-	Name         string     `json:"name"`
-	SystemPrompt string     `json:"system_prompt"`
-	Position     Position   `json:"position"`
-	Memory       []string   `json:"memory"`
-	MaxMemory    int        `json:"-"`
-	IsAdversary  bool       `json:"is_adversary"`
-	AdversaryType string    `json:"adversary_type,omitempty"`
+	mu            sync.RWMutex
+	ID            uuid.UUID     `json:"id"`
+	GameID        uuid.UUID     `json:"game_id"`
+	PlayerID      *uuid.UUID    `json:"player_id,omitempty"`
+	Name          string        `json:"name"`
+	SystemPrompt  string        `json:"system_prompt"`
+	Position      Position      `json:"position"`
+	Memory        []string      `json:"memory"`
+	MaxMemory     int           `json:"-"`
+	IsAdversary   bool          `json:"is_adversary"`
+	AdversaryType string        `json:"adversary_type,omitempty"`
+	Model         ModelSettings `json:"model"` // LLM backend and sampling for this agent
 
 	// Combat & Resource fields
 	HP        int `json:"hp"`
@@ -47,6 +48,10 @@ type Agent struct {
 	DeathTick   int  `json:"death_tick,omitempty"`
 	RespawnTick int  `json:"respawn_tick,omitempty"`
 
+	// Lifetime energy totals for game stats
+	energyEarned int
+	energySpent  int
+
 	// Fog of War - tiles the agent has seen (for persistence)
 	// MULTIPLAYER: Already per-agent, scales naturally
 	ExploredTiles map[string]bool `json:"-"` // "x,y" -> true, not serialized to client
@@ -221,6 +226,7 @@ func (a *Agent) Snapshot() AgentSnapshot {
 		Position:      a.Position,
 		IsAdversary:   a.IsAdversary,
 		AdversaryType: a.AdversaryType,
+		Model:         a.Model.Label(),
 		HP:            a.HP,
 		MaxHP:         a.MaxHP,
 		Energy:        a.Energy,
@@ -243,6 +249,7 @@ type AgentSnapshot struct {
 	Position      Position  `json:"position"`
 	IsAdversary   bool      `json:"is_adversary"`
 	AdversaryType string    `json:"adversary_type,omitempty"`
+	Model         string    `json:"model,omitempty"` // Per-agent LLM, e.g. "openai/gpt-4o-mini"
 	HP            int       `json:"hp"`
 	MaxHP         int       `json:"max_hp"`
 	Energy        int       `json:"energy"`
@@ -301,10 +308,14 @@ func (a *Agent) SetEnergy(energy int) {
 func (a *Agent) AddEnergy(amount int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	before := a.Energy
 	a.Energy += amount
 	if a.Energy > a.MaxEnergy {
 		a.Energy = a.MaxEnergy
 	}
+	if a.Energy > before {
+		a.energyEarned += a.Energy - before
+	}
 }
 
 // SpendEnergy attempts to spend energy, returns true if successful
@@ -315,9 +326,17 @@ func (a *Agent) SpendEnergy(amount int) bool {
 		return false
 	}
 	a.Energy -= amount
+	a.energySpent += amount
 	return true
 }
 
+// EnergyTotals returns the energy the agent has earned and spent so far
+func (a *Agent) EnergyTotals() (earned, spent int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.energyEarned, a.energySpent
+}
+
 // GetCoins returns the agent's coin count (thread-safe)
 func (a *Agent) GetCoins() int {
 	a.mu.RLock()
@@ -497,6 +516,7 @@ func (a *Agent) ApplyUpgrade(upgradeType string) bool {
 	defer a.mu.Unlock()
 
 	a.Energy -= cost
+	a.energySpent += cost
 
 	switch upgradeType {
 	case "vision":
@@ -563,10 +583,12 @@ type AgentContext struct {
 	WorldSize        int
 	CurrentTileOwned bool
 	CurrentTileEnemy bool
-	EnergyPerTick    int    // Passive income from owned tiles
-	MoveSpeed        int    // Effective tiles per MOVE action
-	ClaimRadius      int    // Effective claim radius
-	CurrentBiome     string // Biome type at agent's current position
+	EnergyPerTick    int           // Passive income from owned tiles
+	MoveSpeed        int           // Effective tiles per MOVE action
+	ClaimRadius      int           // Effective claim radius
+	CurrentBiome     string        // Biome type at agent's current position
+	LastResult       *ActionResult // Result of the agent's previous action, nil before its first
+	Reask            bool          // LastResult is this tick's action, rejected by validation; choose again
 }
 
 // IncomingMessage represents a message received by an agent
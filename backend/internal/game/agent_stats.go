@@ -0,0 +1,277 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentStats summarizes how an agent played, accumulated every tick
+type AgentStats struct {
+	AgentID        uuid.UUID `json:"agent_id"`
+	Name           string    `json:"name"`
+	Territory      []int     `json:"territory"` // Tiles owned after each tick
+	FinalTerritory int       `json:"final_territory"`
+	PeakTerritory  int       `json:"peak_territory"`
+
+	TilesClaimed  int `json:"tiles_claimed"`  // Neutral tiles claimed
+	TilesCaptured int `json:"tiles_captured"` // Tiles taken from other agents
+	TilesLost     int `json:"tiles_lost"`     // Tiles taken by others or cleared on death
+
+	Kills       int `json:"kills"`
+	Deaths      int `json:"deaths"`
+	DamageDealt int `json:"damage_dealt"`
+
+	Actions   map[ActionType]int      `json:"actions"`   // Chosen actions by type
+	Failures  map[string]FailureCount `json:"failures"`  // Failed action results by action and reason
+	Fallbacks map[FallbackPolicy]int  `json:"fallbacks"` // Actions chosen by a fallback policy after an LLM failure
+
+	ResourcesHarvested map[string]int `json:"resources_harvested"` // Including territory absorption
+	EnergyEarned       int            `json:"energy_earned"`
+	EnergySpent        int            `json:"energy_spent"`
+
+	LLMRequests      int     `json:"llm_requests"`
+	LLMErrors        int     `json:"llm_errors"`
+	LLMTimeouts      int     `json:"llm_timeouts"`   // Also counted in LLMErrors
+	LLMReasks        int     `json:"llm_reasks"`     // Requests repeated in the same tick after an invalid action
+	LLMLatencyMs     int64   `json:"llm_latency_ms"` // Summed over requests
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"` // Estimated from the configured price table
+}
+
+// FailureCount counts an agent's failed actions of one type for one reason
+type FailureCount struct {
+	Action  ActionType `json:"action"`
+	Reason  string     `json:"reason"` // Result message with numbers and IDs masked, e.g. "need # coins"
+	Count   int        `json:"count"`
+	Example string     `json:"example"` // First message seen for the reason
+}
+
+// maxFailureReasons caps the distinct reasons tracked per agent; further
+// reasons are counted under otherFailureReason
+const maxFailureReasons = 32
+
+const otherFailureReason = "other"
+
+// failureVariables matches the parts of result messages that vary between
+// otherwise identical failures
+var failureVariables = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|-?\d+(\.\d+)?`)
+
+// failureReason reduces a result message to a stable reason code
+func failureReason(message string) string {
+	return failureVariables.ReplaceAllString(message, "#")
+}
+
+// recordFailure counts a failed action result. Caller must hold the tracker's lock.
+func (s *AgentStats) recordFailure(result ActionResult) {
+	reason := failureReason(result.Message)
+	key := string(result.Action) + ": " + reason
+	if _, ok := s.Failures[key]; !ok && len(s.Failures) >= maxFailureReasons {
+		reason = otherFailureReason
+		key = string(result.Action) + ": " + reason
+	}
+	failure, ok := s.Failures[key]
+	if !ok {
+		failure = FailureCount{Action: result.Action, Reason: reason, Example: result.Message}
+	}
+	failure.Count++
+	s.Failures[key] = failure
+}
+
+// usage returns the agent's LLM usage totals
+func (s AgentStats) usage() Usage {
+	return Usage{
+		Requests:         s.LLMRequests,
+		Errors:           s.LLMErrors,
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		LatencyMs:        s.LLMLatencyMs,
+		CostUSD:          s.CostUSD,
+	}
+}
+
+// statsTracker holds per-agent stats. It has its own lock because LLM
+// errors are recorded from the request goroutines.
+type statsTracker struct {
+	mu     sync.Mutex
+	agents map[uuid.UUID]*AgentStats
+}
+
+// get returns an agent's stats, creating them if needed. Caller must hold s.mu.
+func (s *statsTracker) get(agentID uuid.UUID) *AgentStats {
+	if s.agents == nil {
+		s.agents = make(map[uuid.UUID]*AgentStats)
+	}
+	stats, ok := s.agents[agentID]
+	if !ok {
+		stats = &AgentStats{
+			AgentID:            agentID,
+			Actions:            make(map[ActionType]int),
+			Failures:           make(map[string]FailureCount),
+			Fallbacks:          make(map[FallbackPolicy]int),
+			ResourcesHarvested: make(map[string]int),
+		}
+		s.agents[agentID] = stats
+	}
+	return stats
+}
+
+// recordLLMRequest counts an agent's LLM request with the token usage its
+// responses reported
+func (s *statsTracker) recordLLMRequest(agentID uuid.UUID, latency time.Duration, tokens []TokenUsage, err error) {
+	s.mu.Lock()
+	stats := s.get(agentID)
+	stats.LLMRequests++
+	stats.LLMLatencyMs += latency.Milliseconds()
+	for _, t := range tokens {
+		stats.PromptTokens += t.PromptTokens
+		stats.CompletionTokens += t.CompletionTokens
+		stats.CostUSD += t.CostUSD
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.recordLLMError(agentID, err)
+	}
+}
+
+// recordReask counts a repeated request after an invalid action
+func (s *statsTracker) recordReask(agentID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(agentID).LLMReasks++
+}
+
+// costUSD returns the estimated LLM cost of all agents
+func (s *statsTracker) costUSD() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cost float64
+	for _, stats := range s.agents {
+		cost += stats.CostUSD
+	}
+	return cost
+}
+
+// recordLLMError counts a failed LLM request for an agent
+func (s *statsTracker) recordLLMError(agentID uuid.UUID, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.get(agentID)
+	stats.LLMErrors++
+	if errors.Is(err, context.DeadlineExceeded) {
+		stats.LLMTimeouts++
+	}
+}
+
+// recordStats folds a processed tick into the per-agent stats
+func (e *Engine) recordStats(actions []Action, results []ActionResult) {
+	e.mu.RLock()
+	agents := make([]*Agent, 0, len(e.agents))
+	for _, agent := range e.agents {
+		agents = append(agents, agent)
+	}
+	e.mu.RUnlock()
+	territory := e.world.GetOwnershipMap()
+
+	s := &e.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, action := range actions {
+		stats := s.get(action.AgentID)
+		stats.Actions[action.Type]++
+		if action.Fallback != "" {
+			stats.Fallbacks[action.Fallback]++
+		}
+	}
+
+	for _, result := range results {
+		stats := s.get(result.AgentID)
+		if !result.Success {
+			if result.Message != "" {
+				stats.recordFailure(result)
+			}
+			continue
+		}
+
+		captured := 0
+		for victim, count := range result.CapturedFrom {
+			captured += count
+			s.get(victim).TilesLost += count
+		}
+		stats.TilesCaptured += captured
+		stats.TilesClaimed += len(result.ClaimedTiles) - captured
+
+		if result.Harvested != "" {
+			stats.ResourcesHarvested[result.Harvested] += result.ItemQuantity
+		}
+		if result.Action == ActionFight && result.TargetID != nil {
+			stats.DamageDealt += result.DamageDealt
+		}
+		if result.Killed != nil {
+			victim := s.get(*result.Killed)
+			victim.Deaths++
+			victim.TilesLost += len(result.ClearedTiles)
+			if *result.Killed != result.AgentID {
+				stats.Kills++
+			}
+		}
+	}
+
+	for _, agent := range agents {
+		stats := s.get(agent.ID)
+		stats.Name = agent.Name
+		owned := territory[agent.ID]
+		stats.Territory = append(stats.Territory, owned)
+		stats.FinalTerritory = owned
+		stats.PeakTerritory = max(stats.PeakTerritory, owned)
+		stats.EnergyEarned, stats.EnergySpent = agent.EnergyTotals()
+	}
+}
+
+// Stats returns a copy of every agent's stats, sorted by name
+func (e *Engine) Stats() []AgentStats {
+	s := &e.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]AgentStats, 0, len(s.agents))
+	for _, stats := range s.agents {
+		c := *stats
+		c.Territory = append([]int(nil), stats.Territory...)
+		c.Actions = make(map[ActionType]int, len(stats.Actions))
+		for k, v := range stats.Actions {
+			c.Actions[k] = v
+		}
+		c.Failures = make(map[string]FailureCount, len(stats.Failures))
+		for k, v := range stats.Failures {
+			c.Failures[k] = v
+		}
+		c.Fallbacks = make(map[FallbackPolicy]int, len(stats.Fallbacks))
+		for k, v := range stats.Fallbacks {
+			c.Fallbacks[k] = v
+		}
+		c.ResourcesHarvested = make(map[string]int, len(stats.ResourcesHarvested))
+		for k, v := range stats.ResourcesHarvested {
+			c.ResourcesHarvested[k] = v
+		}
+		out = append(out, c)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].AgentID.String() < out[j].AgentID.String()
+	})
+	return out
+}
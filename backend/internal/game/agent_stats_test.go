@@ -0,0 +1,146 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+)
+
+func newStatsTestEngine(t *testing.T) (*Engine, *Agent, *Agent) {
+	t.Helper()
+	m, err := ParseAuthoredMap([]byte("1...\n....\n....\n...2"))
+	if err != nil {
+		t.Fatalf("failed to parse map: %v", err)
+	}
+	cfg := config.Default().Game
+	cfg.MapSize = m.Size()
+	engine := NewEngineWithAuthoredMap(uuid.New(), cfg, config.DefaultBalanceConfig(), m, nil, nil, nil, 1)
+
+	alice := NewAgent(engine.ID, "Alice", "", Position{}, 5)
+	bob := NewAgent(engine.ID, "Bob", "", Position{}, 5)
+	for _, agent := range []*Agent{bob, alice} {
+		if err := engine.AddAgent(agent); err != nil {
+			t.Fatalf("failed to add agent: %v", err)
+		}
+	}
+	return engine, alice, bob
+}
+
+func TestRecordStats(t *testing.T) {
+	engine, alice, bob := newStatsTestEngine(t)
+	world := engine.GetWorld()
+	world.SetOwner(Position{X: 0, Y: 0}, &alice.ID)
+	world.SetOwner(Position{X: 1, Y: 0}, &alice.ID)
+	world.SetOwner(Position{X: 3, Y: 3}, &bob.ID)
+
+	actions := []Action{
+		{AgentID: alice.ID, Type: ActionClaim},
+		{AgentID: bob.ID, Type: ActionFight},
+		{AgentID: bob.ID, Type: ActionMove},
+	}
+	results := []ActionResult{
+		{
+			AgentID:      alice.ID,
+			Action:       ActionClaim,
+			Success:      true,
+			ClaimedTiles: []Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+			CapturedFrom: map[uuid.UUID]int{bob.ID: 1},
+		},
+		{AgentID: bob.ID, Action: ActionMove, Success: false, Message: "tile is blocked"},
+		{AgentID: alice.ID, Action: ActionHarvest, Success: true, Harvested: "wood", ItemQuantity: 2},
+		{
+			AgentID:      bob.ID,
+			Action:       ActionFight,
+			Success:      true,
+			TargetID:     &alice.ID,
+			DamageDealt:  3,
+			Killed:       &alice.ID,
+			ClearedTiles: []Position{{X: 9, Y: 9}},
+		},
+	}
+	engine.recordStats(actions, results)
+	engine.recordStats(nil, nil)
+
+	stats := engine.Stats()
+	if len(stats) != 2 || stats[0].Name != "Alice" || stats[1].Name != "Bob" {
+		t.Fatalf("expected stats for Alice and Bob in order, got %+v", stats)
+	}
+	a, b := stats[0], stats[1]
+
+	if a.TilesClaimed != 2 || a.TilesCaptured != 1 {
+		t.Errorf("expected Alice to claim 2 and capture 1 tile, got %d and %d", a.TilesClaimed, a.TilesCaptured)
+	}
+	if b.TilesLost != 1 || a.TilesLost != 1 {
+		t.Errorf("expected both agents to lose 1 tile, got Alice %d Bob %d", a.TilesLost, b.TilesLost)
+	}
+	if b.Kills != 1 || a.Deaths != 1 || b.DamageDealt != 3 {
+		t.Errorf("expected Bob to kill Alice for 3 damage, got %+v / %+v", a, b)
+	}
+	if a.Actions[ActionClaim] != 1 || b.Actions[ActionMove] != 1 || b.Actions[ActionFight] != 1 {
+		t.Errorf("unexpected action counts: Alice %v Bob %v", a.Actions, b.Actions)
+	}
+	if b.Failures["MOVE: tile is blocked"].Count != 1 {
+		t.Errorf("expected Bob's failed move to be counted, got %v", b.Failures)
+	}
+	if a.ResourcesHarvested["wood"] != 2 {
+		t.Errorf("expected Alice to harvest 2 wood, got %v", a.ResourcesHarvested)
+	}
+	if fmt.Sprint(a.Territory) != "[2 2]" || fmt.Sprint(b.Territory) != "[1 1]" {
+		t.Errorf("expected territory samples for both ticks, got Alice %v Bob %v", a.Territory, b.Territory)
+	}
+	if a.FinalTerritory != 2 || a.PeakTerritory != 2 {
+		t.Errorf("expected Alice final and peak territory 2, got %d and %d", a.FinalTerritory, a.PeakTerritory)
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	stats := AgentStats{Failures: make(map[string]FailureCount)}
+	stats.recordFailure(ActionResult{Action: ActionBuy, Message: "need 15 coins (have 3)"})
+	stats.recordFailure(ActionResult{Action: ActionBuy, Message: "need 20 coins (have 1)"})
+	stats.recordFailure(ActionResult{Action: ActionFight, Message: "agent " + uuid.NewString() + " is not adjacent"})
+
+	buy := stats.Failures["BUY: need # coins (have #)"]
+	if buy.Count != 2 || buy.Example != "need 15 coins (have 3)" {
+		t.Errorf("expected both purchases under one reason, got %v", stats.Failures)
+	}
+	if stats.Failures["FIGHT: agent # is not adjacent"].Count != 1 {
+		t.Errorf("expected the agent ID to be masked, got %v", stats.Failures)
+	}
+
+	for i := 0; i < 2*maxFailureReasons; i++ {
+		stats.recordFailure(ActionResult{Action: ActionMove, Message: fmt.Sprintf("failure %c", 'A'+i)})
+	}
+	if len(stats.Failures) > maxFailureReasons+1 {
+		t.Errorf("expected at most %d reasons, got %d", maxFailureReasons, len(stats.Failures))
+	}
+	if stats.Failures["MOVE: other"].Count == 0 {
+		t.Errorf("expected overflowing reasons to be counted as other, got %v", stats.Failures)
+	}
+}
+
+func TestRecordLLMError(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.stats.recordLLMError(alice.ID, errors.New("bad response"))
+	engine.stats.recordLLMError(alice.ID, fmt.Errorf("request failed: %w", context.DeadlineExceeded))
+
+	for _, stats := range engine.Stats() {
+		if stats.AgentID == alice.ID && (stats.LLMErrors != 2 || stats.LLMTimeouts != 1) {
+			t.Errorf("expected 2 errors and 1 timeout, got %d and %d", stats.LLMErrors, stats.LLMTimeouts)
+		}
+	}
+}
+
+func TestAgentEnergyTotals(t *testing.T) {
+	agent := NewAgent(uuid.New(), "Alice", "", Position{}, 5)
+	agent.AddEnergy(10)
+	agent.SpendEnergy(4)
+
+	earned, spent := agent.EnergyTotals()
+	if earned != 10 || spent != 4 {
+		t.Errorf("expected 10 earned and 4 spent, got %d and %d", earned, spent)
+	}
+}
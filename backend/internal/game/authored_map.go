@@ -0,0 +1,312 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lucas/promptlands/internal/game/worldgen"
+)
+
+// Limits for hand-authored maps
+const (
+	MinAuthoredMapSize = 4
+	MaxAuthoredMapSize = 1024
+)
+
+// Default resource amounts and rewards for authored objects that omit them
+const (
+	defaultAuthoredResource = 5
+	defaultAuthoredCache    = 3
+)
+
+// DefaultMapLegend maps ASCII grid characters to biomes
+var DefaultMapLegend = map[rune]worldgen.BiomeType{
+	'.': worldgen.BiomeSavanna,
+	'T': worldgen.BiomeForest,
+	'd': worldgen.BiomeDesert,
+	'v': worldgen.BiomeVolcanic,
+	'i': worldgen.BiomeIce,
+	'b': worldgen.BiomeBadlands,
+	'%': worldgen.BiomeSwamp,
+	'*': worldgen.BiomeCrystal,
+	'x': worldgen.BiomeVoid,
+	'n': worldgen.BiomeNeon,
+	'p': worldgen.BiomePlasma,
+	'a': worldgen.BiomeAncient,
+	'~': worldgen.BiomeOcean,
+	'^': worldgen.BiomeMountain,
+}
+
+// Grid markers place objects or spawn points on top of the map's default biome.
+// Digits 1-9 are spawn points, filled in numeric order.
+var mapObjectMarkers = map[rune]string{
+	'S': string(InteractiveShrine),
+	'C': string(InteractiveCache),
+	'O': string(InteractiveObelisk),
+	'W': string(ResourceWood),
+	'R': string(ResourceStone),
+	'K': string(ResourceCrystal),
+	'H': string(ResourceHerb),
+}
+
+// AuthoredMap is a hand-made map loaded instead of procedural terrain. It can
+// be written as JSON or as a plain ASCII grid using DefaultMapLegend.
+type AuthoredMap struct {
+	Name         string            `json:"name,omitempty"`
+	Grid         []string          `json:"grid"`                    // One row per line, one character per tile
+	Legend       map[string]string `json:"legend,omitempty"`        // Extra character -> biome mappings (markers cannot be remapped)
+	DefaultBiome string            `json:"default_biome,omitempty"` // Biome under markers and padding (default savanna)
+	Objects      []AuthoredObject  `json:"objects,omitempty"`
+	Spawns       []Position        `json:"spawns,omitempty"` // Fixed spawn points, used in order
+
+	// ResourceSpawnRate overrides the per-tick biome resource spawning
+	// multiplier, e.g. 0 for a fully static puzzle
+	ResourceSpawnRate *float64 `json:"resource_spawn_rate,omitempty"`
+
+	// Filled in by Parse
+	size    int
+	biomes  [][]worldgen.BiomeType
+	spawns  []Position       // Spawns followed by numbered grid markers
+	objects []AuthoredObject // Objects followed by grid markers
+}
+
+// AuthoredObject places an interactive or resource node on an authored map
+type AuthoredObject struct {
+	Kind        string    `json:"kind"` // shrine, cache, portal, obelisk, or a resource: wood, stone, crystal, herb
+	Position    Position  `json:"position"`
+	Destination *Position `json:"destination,omitempty"` // Portals
+	Energy      int       `json:"energy,omitempty"`      // Caches
+	Remaining   int       `json:"remaining,omitempty"`   // Resources
+	Message     string    `json:"message,omitempty"`     // Obelisks
+}
+
+// UnmarshalJSON accepts either a map object or a string holding an ASCII grid
+func (m *AuthoredMap) UnmarshalJSON(data []byte) error {
+	var ascii string
+	if err := json.Unmarshal(data, &ascii); err == nil {
+		parsed, err := ParseAuthoredMap([]byte(ascii))
+		if err != nil {
+			return err
+		}
+		*m = *parsed
+		return nil
+	}
+
+	type plain AuthoredMap
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	return m.Parse()
+}
+
+// ParseAuthoredMap parses a JSON map or an ASCII grid
+func ParseAuthoredMap(data []byte) (*AuthoredMap, error) {
+	trimmed := bytes.TrimSpace(data)
+	m := &AuthoredMap{}
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, m); err != nil {
+			return nil, fmt.Errorf("invalid map: %w", err)
+		}
+		return m, nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue // Blank lines and comments
+		}
+		m.Grid = append(m.Grid, strings.TrimRight(line, " \t"))
+	}
+	if err := m.Parse(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadAuthoredMap loads a map from a JSON or ASCII file
+func LoadAuthoredMap(path string) (*AuthoredMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAuthoredMap(data)
+}
+
+// Size returns the side length of the (square) map
+func (m *AuthoredMap) Size() int {
+	return m.size
+}
+
+// SpawnPoints returns the fixed spawn points in the order they are used
+func (m *AuthoredMap) SpawnPoints() []Position {
+	return m.spawns
+}
+
+// Parse decodes the grid into biomes, collects grid markers into objects and
+// spawns, and validates everything against the map bounds
+func (m *AuthoredMap) Parse() error {
+	registry := worldgen.DefaultBiomeRegistry()
+
+	legend := make(map[rune]worldgen.BiomeType, len(DefaultMapLegend)+len(m.Legend))
+	for ch, biome := range DefaultMapLegend {
+		legend[ch] = biome
+	}
+	for key, biome := range m.Legend {
+		runes := []rune(key)
+		if len(runes) != 1 {
+			return fmt.Errorf("legend key %q must be a single character", key)
+		}
+		if _, ok := registry.GetBiome(worldgen.BiomeType(biome)); !ok {
+			return fmt.Errorf("legend %q: unknown biome %q", key, biome)
+		}
+		legend[runes[0]] = worldgen.BiomeType(biome)
+	}
+
+	defaultBiome := worldgen.BiomeSavanna
+	if m.DefaultBiome != "" {
+		defaultBiome = worldgen.BiomeType(m.DefaultBiome)
+		if _, ok := registry.GetBiome(defaultBiome); !ok {
+			return fmt.Errorf("unknown default_biome %q", m.DefaultBiome)
+		}
+	}
+
+	// Square the grid: size is the longer of width and height
+	size := len(m.Grid)
+	for _, row := range m.Grid {
+		if n := len([]rune(row)); n > size {
+			size = n
+		}
+	}
+	if size < MinAuthoredMapSize || size > MaxAuthoredMapSize {
+		return fmt.Errorf("map must be between %d and %d tiles wide", MinAuthoredMapSize, MaxAuthoredMapSize)
+	}
+
+	biomes := make([][]worldgen.BiomeType, size)
+	numbered := make(map[int]Position)
+	var markerObjects []AuthoredObject
+	for y := 0; y < size; y++ {
+		biomes[y] = make([]worldgen.BiomeType, size)
+		var row []rune
+		if y < len(m.Grid) {
+			row = []rune(m.Grid[y])
+		}
+		for x := 0; x < size; x++ {
+			biomes[y][x] = defaultBiome
+			if x >= len(row) || row[x] == ' ' {
+				continue
+			}
+			ch := row[x]
+			pos := Position{X: x, Y: y}
+			switch {
+			case ch >= '1' && ch <= '9':
+				if _, dup := numbered[int(ch-'0')]; dup {
+					return fmt.Errorf("spawn point %c appears more than once", ch)
+				}
+				numbered[int(ch-'0')] = pos
+			case mapObjectMarkers[ch] != "":
+				markerObjects = append(markerObjects, AuthoredObject{Kind: mapObjectMarkers[ch], Position: pos})
+			case legend[ch] != "":
+				biomes[y][x] = legend[ch]
+			default:
+				return fmt.Errorf("unknown map character %q at (%d, %d)", ch, x, y)
+			}
+		}
+	}
+
+	spawns := append([]Position(nil), m.Spawns...)
+	for n := 1; n <= 9; n++ {
+		if pos, ok := numbered[n]; ok {
+			spawns = append(spawns, pos)
+		}
+	}
+	objects := append(append([]AuthoredObject(nil), m.Objects...), markerObjects...)
+
+	passable := func(pos Position) bool {
+		return registry.IsPassable(biomes[pos.Y][pos.X])
+	}
+	inBounds := func(pos Position) bool {
+		return pos.X >= 0 && pos.Y >= 0 && pos.X < size && pos.Y < size
+	}
+
+	seen := make(map[Position]bool)
+	for i, pos := range spawns {
+		if !inBounds(pos) || !passable(pos) {
+			return fmt.Errorf("spawn %d at (%d, %d) is out of bounds or impassable", i+1, pos.X, pos.Y)
+		}
+		if seen[pos] {
+			return fmt.Errorf("spawn %d at (%d, %d) is used twice", i+1, pos.X, pos.Y)
+		}
+		seen[pos] = true
+	}
+
+	for i, obj := range objects {
+		if !inBounds(obj.Position) || !passable(obj.Position) {
+			return fmt.Errorf("object %d (%s) is out of bounds or impassable", i, obj.Kind)
+		}
+		switch obj.Kind {
+		case string(InteractivePortal):
+			if obj.Destination == nil || !inBounds(*obj.Destination) || !passable(*obj.Destination) {
+				return fmt.Errorf("object %d: portal needs a passable destination", i)
+			}
+		case string(InteractiveShrine), string(InteractiveCache), string(InteractiveObelisk),
+			string(ResourceWood), string(ResourceStone), string(ResourceCrystal), string(ResourceHerb):
+		default:
+			return fmt.Errorf("object %d: unknown kind %q", i, obj.Kind)
+		}
+	}
+
+	m.size = size
+	m.biomes = biomes
+	m.spawns = spawns
+	m.objects = objects
+	return nil
+}
+
+// buildWorld creates the world tiles from the parsed grid
+func (m *AuthoredMap) buildWorld(seed int64) *World {
+	registry := worldgen.DefaultBiomeRegistry()
+	tiles := make([][]*Tile, m.size)
+	for y := 0; y < m.size; y++ {
+		tiles[y] = make([]*Tile, m.size)
+		for x := 0; x < m.size; x++ {
+			biome := m.biomes[y][x]
+			tiles[y][x] = &Tile{
+				Position: Position{X: x, Y: y},
+				Terrain:  TerrainType(registry.GetTerrainClass(biome)),
+				Biome:    string(biome),
+			}
+		}
+	}
+	return NewWorldWithSeed(m.size, seed, tiles)
+}
+
+// populate adds the authored objects to the world object manager
+func (m *AuthoredMap) populate(objects *WorldObjectManager) {
+	for _, obj := range m.objects {
+		var wo *WorldObject
+		switch obj.Kind {
+		case string(InteractiveShrine):
+			wo = NewShrine(obj.Position)
+		case string(InteractiveCache):
+			energy := obj.Energy
+			if energy <= 0 {
+				energy = defaultAuthoredCache
+			}
+			wo = NewCache(obj.Position, energy)
+		case string(InteractivePortal):
+			wo = NewPortal(obj.Position, *obj.Destination)
+		case string(InteractiveObelisk):
+			wo = NewObelisk(obj.Position)
+			wo.Message = obj.Message
+		default:
+			remaining := obj.Remaining
+			if remaining <= 0 {
+				remaining = defaultAuthoredResource
+			}
+			wo = NewResourceNode(ResourceType(obj.Kind), obj.Position, remaining)
+		}
+		objects.Add(wo)
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+)
+
+func TestParseAuthoredMap_ASCII(t *testing.T) {
+	m, err := LoadAuthoredMap("../../maps/duel_arena.txt")
+	if err != nil {
+		t.Fatalf("failed to load arena: %v", err)
+	}
+
+	if m.Size() != 16 {
+		t.Errorf("expected size 16, got %d", m.Size())
+	}
+	spawns := m.SpawnPoints()
+	if len(spawns) != 2 || spawns[0] != (Position{X: 2, Y: 2}) || spawns[1] != (Position{X: 13, Y: 13}) {
+		t.Errorf("unexpected spawns: %v", spawns)
+	}
+	if m.biomes[0][0] != "ocean" || m.biomes[3][7] != "forest" {
+		t.Errorf("unexpected biomes at (0,0)=%s (7,3)=%s", m.biomes[0][0], m.biomes[3][7])
+	}
+}
+
+func TestParseAuthoredMap_JSON(t *testing.T) {
+	data := `{
+		"grid": ["....", ".12.", "....", "...."],
+		"objects": [{"kind": "portal", "position": {"x": 0, "y": 0}, "destination": {"x": 3, "y": 3}}],
+		"spawns": [{"x": 3, "y": 0}]
+	}`
+
+	var m AuthoredMap
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Explicit spawns come before numbered markers
+	want := []Position{{X: 3, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}}
+	got := m.SpawnPoints()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d spawns, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("spawn %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestParseAuthoredMap_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"too small":           "..\n..",
+		"unknown character":   "....\n..?.\n....\n....",
+		"duplicate spawn":     "....\n.11.\n....\n....",
+		"spawn on water":      `{"grid": ["~~~~", "~~~~", "~~~~", "~~~~"], "spawns": [{"x": 1, "y": 1}]}`,
+		"portal without exit": `{"grid": ["....", "....", "....", "...."], "objects": [{"kind": "portal", "position": {"x": 1, "y": 1}}]}`,
+		"unknown object":      `{"grid": ["....", "....", "....", "...."], "objects": [{"kind": "dragon", "position": {"x": 1, "y": 1}}]}`,
+	}
+
+	for name, data := range cases {
+		if _, err := ParseAuthoredMap([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestNewEngineWithAuthoredMap(t *testing.T) {
+	m, err := ParseAuthoredMap([]byte("1...\n.S..\n..W.\n...2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := config.Default().Game
+	cfg.MapSize = m.Size()
+	engine := NewEngineWithAuthoredMap(uuid.New(), cfg, config.DefaultBalanceConfig(), m, nil, nil, nil, 1)
+
+	if engine.GetWorld().Size() != 4 {
+		t.Errorf("expected world size 4, got %d", engine.GetWorld().Size())
+	}
+	if obj := engine.worldObjects.GetInteractiveAt(Position{X: 1, Y: 1}); obj == nil || obj.InteractiveType != InteractiveShrine {
+		t.Error("expected shrine at (1,1)")
+	}
+	if obj := engine.worldObjects.GetResourceAt(Position{X: 2, Y: 2}); obj == nil || obj.ResourceType != ResourceWood {
+		t.Error("expected wood at (2,2)")
+	}
+	if len(engine.worldObjects.GetAll()) != 2 {
+		t.Errorf("expected only authored objects, got %d", len(engine.worldObjects.GetAll()))
+	}
+	if pos := findAvailableSpawnPosition(engine, 4); pos != (Position{X: 0, Y: 0}) {
+		t.Errorf("expected first fixed spawn, got %v", pos)
+	}
+}
@@ -136,6 +136,7 @@ func (ap *ActionProcessor) ProcessAll(actions []Action) []ActionResult {
 	for i, action := range actions {
 		results[i] = ap.Process(action)
 		results[i].Reasoning = action.Reasoning
+		results[i].Fallback = action.Fallback
 	}
 	return results
 }
@@ -2,9 +2,10 @@ package game
 
 import (
 	"context"
-	"log"
 	"sync"
 	"time"
+
+	"github.com/lucas/promptlands/internal/logging"
 )
 
 // buildAgentContexts creates context for each agent
@@ -93,16 +94,40 @@ func (e *Engine) buildAgentContexts(agents []*Agent) []AgentContext {
 			MoveSpeed:        agent.GetEffectiveMoveSpeed(e.balance.Agent.DefaultMoveSpeed),
 			ClaimRadius:      agent.GetEffectiveClaimRadius(e.balance.Agent.DefaultClaimRadius),
 			CurrentBiome:     currentBiome,
+			LastResult:       e.lastResult(agent.ID),
 		}
 	}
 
 	return contexts
 }
 
+type agentContextKey struct{}
+
+// WithAgentContext attaches the structured view an agent's prompt was built
+// from, for clients such as scripted bots that don't read the prompt text
+func WithAgentContext(ctx context.Context, actx AgentContext) context.Context {
+	return context.WithValue(ctx, agentContextKey{}, actx)
+}
+
+// AgentContextFromContext returns the agent context attached to ctx, if any
+func AgentContextFromContext(ctx context.Context) (AgentContext, bool) {
+	actx, ok := ctx.Value(agentContextKey{}).(AgentContext)
+	return actx, ok
+}
+
 // requestActions gets actions from all agents in parallel
 func (e *Engine) requestActions(ctx context.Context, contexts []AgentContext) []Action {
+	ctx = WithGameID(ctx, e.ID)
+	if e.actionSchema != nil {
+		ctx = WithActionSchema(ctx, e.actionSchema)
+	}
 	var wg sync.WaitGroup
 	actions := make([]Action, len(contexts))
+	record := e.RecordsPrompts()
+	var records []PromptRecord
+	if record {
+		records = make([]PromptRecord, len(contexts))
+	}
 
 	for i, agentCtx := range contexts {
 		wg.Add(1)
@@ -110,16 +135,54 @@ func (e *Engine) requestActions(ctx context.Context, contexts []AgentContext) []
 			defer wg.Done()
 
 			prompt := e.promptBuilder.BuildPrompt(actx)
-			action, err := e.llmClient.GetAction(ctx, actx.Agent.ID, prompt)
+			var tokens []TokenUsage
+			reqCtx := WithUsageReporter(WithModelSettings(WithAgentContext(ctx, actx), actx.Agent.Model), func(usage TokenUsage) {
+				tokens = append(tokens, usage)
+			})
+			thinking := e.newThinkingBroadcaster(actx.CurrentTick, actx.Agent.ID)
+			if thinking != nil {
+				reqCtx = WithThinkingStream(reqCtx, thinking.add)
+			}
+			start := time.Now()
+			action, err := e.llmClient.GetAction(reqCtx, actx.Agent.ID, prompt)
+			latency := time.Since(start)
+			if thinking != nil {
+				thinking.flush()
+			}
+			e.llmStats.record(latency, err)
+			e.stats.recordLLMRequest(actx.Agent.ID, latency, tokens, err)
+			if err == nil {
+				if reaskPrompt, retry, ok := e.reask(ctx, actx, action, latency); ok {
+					prompt, action = reaskPrompt, retry
+				}
+			}
 			if err != nil {
-				log.Printf("LLM error for agent %s: %v", actx.Agent.Name, err)
-				action = WaitAction(actx.Agent.ID)
+				logging.FromContext(ctx).Warn("LLM request failed", logging.KeyAgentID, actx.Agent.ID, "agent", actx.Agent.Name, "error", err)
+				raw := action.Raw
+				action = e.fallbackAction(ctx, actx, prompt)
+				action.Raw = raw
 			}
 			action.ReceivedAt = time.Now()
 			actions[idx] = action
+
+			if record {
+				records[idx] = PromptRecord{
+					Tick:     actx.CurrentTick,
+					AgentID:  actx.Agent.ID,
+					Prompt:   prompt,
+					Response: action.Raw,
+					Action:   action,
+				}
+				if err != nil {
+					records[idx].Error = err.Error()
+				}
+			}
 		}(i, agentCtx)
 	}
 
 	wg.Wait()
+	if record {
+		e.appendPrompts(records)
+	}
 	return actions
 }
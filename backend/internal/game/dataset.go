@@ -0,0 +1,144 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRewardTicks is how far ahead dataset rewards look by default
+const DefaultRewardTicks = 5
+
+// PromptRecord is one recorded LLM exchange
+type PromptRecord struct {
+	Tick     int       `json:"tick"`
+	AgentID  uuid.UUID `json:"agent_id"`
+	Prompt   string    `json:"prompt"`
+	Response string    `json:"response"` // Raw model output, empty when the request failed
+	Action   Action    `json:"action"`   // Parsed action (WAIT on errors)
+	Error    string    `json:"error,omitempty"`
+}
+
+// DatasetRecord is one line of a training dataset export: what an agent was
+// asked, what it answered and how it worked out
+type DatasetRecord struct {
+	GameID   uuid.UUID     `json:"game_id"`
+	Tick     int           `json:"tick"`
+	AgentID  uuid.UUID     `json:"agent_id"`
+	Agent    string        `json:"agent"`
+	Prompt   string        `json:"prompt"`
+	Response string        `json:"response"`
+	Action   Action        `json:"action"`
+	Error    string        `json:"error,omitempty"`
+	Result   *ActionResult `json:"result,omitempty"`
+
+	Territory   int `json:"territory"`    // Tiles owned after this tick
+	Reward      int `json:"reward"`       // Territory change over the next RewardTicks ticks
+	RewardTicks int `json:"reward_ticks"` // Fewer than requested near the end of the game
+}
+
+// SetRecordPrompts controls whether prompts and raw responses are kept for
+// dataset export. Recording costs memory, so it is opt-in.
+func (e *Engine) SetRecordPrompts(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recordPrompts = enabled
+}
+
+// RecordsPrompts reports whether prompt recording is enabled
+func (e *Engine) RecordsPrompts() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.recordPrompts
+}
+
+// appendPrompts stores a tick's LLM exchanges when recording is enabled
+func (e *Engine) appendPrompts(records []PromptRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.recordPrompts {
+		e.prompts = append(e.prompts, records...)
+	}
+}
+
+// Dataset joins recorded prompts with the tick history. Rewards look
+// rewardTicks ahead (0 = DefaultRewardTicks).
+func (e *Engine) Dataset(rewardTicks int) []DatasetRecord {
+	if rewardTicks <= 0 {
+		rewardTicks = DefaultRewardTicks
+	}
+
+	e.mu.RLock()
+	prompts := make([]PromptRecord, len(e.prompts))
+	copy(prompts, e.prompts)
+	e.mu.RUnlock()
+
+	history := e.History()
+	tickIndex := make(map[int]int, len(history))
+	for i, record := range history {
+		tickIndex[record.Tick] = i
+	}
+
+	stats := make(map[uuid.UUID]AgentStats)
+	for _, s := range e.Stats() {
+		stats[s.AgentID] = s
+	}
+	// territoryAt returns an agent's territory after the history entry at i,
+	// aligning shorter series to the end of the history
+	territoryAt := func(agentID uuid.UUID, i int) int {
+		series := stats[agentID].Territory
+		if idx := i - (len(history) - len(series)); idx >= 0 && idx < len(series) {
+			return series[idx]
+		}
+		return 0
+	}
+
+	records := make([]DatasetRecord, 0, len(prompts))
+	for _, p := range prompts {
+		record := DatasetRecord{
+			GameID:   e.ID,
+			Tick:     p.Tick,
+			AgentID:  p.AgentID,
+			Agent:    stats[p.AgentID].Name,
+			Prompt:   p.Prompt,
+			Response: p.Response,
+			Action:   p.Action,
+			Error:    p.Error,
+		}
+
+		i, ok := tickIndex[p.Tick]
+		if !ok {
+			records = append(records, record) // Tick still in progress
+			continue
+		}
+		for _, result := range history[i].Results {
+			if result.AgentID == p.AgentID {
+				result := result
+				record.Result = &result // The action's own result comes first
+				break
+			}
+		}
+
+		end := min(i+rewardTicks, len(history)-1)
+		record.Territory = territoryAt(p.AgentID, i)
+		record.Reward = territoryAt(p.AgentID, end) - record.Territory
+		record.RewardTicks = end - i
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(a, b int) bool { return records[a].Tick < records[b].Tick })
+	return records
+}
+
+// WriteDataset writes Dataset(rewardTicks) as JSON lines
+func (e *Engine) WriteDataset(w io.Writer, rewardTicks int) error {
+	enc := json.NewEncoder(w)
+	for _, record := range e.Dataset(rewardTicks) {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDataset(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.SetRecordPrompts(true)
+	world := engine.GetWorld()
+
+	// Alice grows from 1 to 4 tiles over three ticks
+	for tick := 1; tick <= 3; tick++ {
+		world.SetOwner(Position{X: tick, Y: 0}, &alice.ID)
+		if tick == 1 {
+			world.SetOwner(Position{X: 0, Y: 0}, &alice.ID)
+		}
+		action := ClaimAction(alice.ID)
+		action.Raw = `{"action":"CLAIM"}`
+		engine.appendPrompts([]PromptRecord{{Tick: tick, AgentID: alice.ID, Prompt: "prompt", Response: action.Raw, Action: action}})
+		results := []ActionResult{{AgentID: alice.ID, Action: ActionClaim, Success: true}}
+		engine.recordStats([]Action{action}, results)
+		engine.recordTick(tick, TickChanges{Results: results})
+	}
+
+	records := engine.Dataset(2)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	first := records[0]
+	if first.Territory != 2 || first.Reward != 2 || first.RewardTicks != 2 {
+		t.Errorf("expected territory 2 and reward 2 over 2 ticks, got %d, %d over %d", first.Territory, first.Reward, first.RewardTicks)
+	}
+	if first.Result == nil || first.Result.Action != ActionClaim || first.Agent != "Alice" {
+		t.Errorf("expected Alice's claim result to be joined, got %+v", first)
+	}
+	if last := records[2]; last.Reward != 0 || last.RewardTicks != 0 {
+		t.Errorf("expected no lookahead for the last tick, got reward %d over %d", last.Reward, last.RewardTicks)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.WriteDataset(&buf, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record DatasetRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		if record.Response != `{"action":"CLAIM"}` {
+			t.Errorf("expected raw response to be exported, got %q", record.Response)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 JSON lines, got %d", lines)
+	}
+}
+
+func TestAppendPrompts_Disabled(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.appendPrompts([]PromptRecord{{Tick: 1, AgentID: alice.ID}})
+	if records := engine.Dataset(0); len(records) != 0 {
+		t.Errorf("expected nothing recorded while disabled, got %d records", len(records))
+	}
+}
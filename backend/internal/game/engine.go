@@ -3,7 +3,7 @@ package game
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
 	"sync"
 	"time"
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/config"
 	"github.com/lucas/promptlands/internal/game/worldgen"
+	"github.com/lucas/promptlands/internal/logging"
 )
 
 // GameStatus represents the current state of a game
@@ -18,6 +19,7 @@ type GameStatus string
 
 const (
 	StatusWaiting  GameStatus = "waiting"
+	StatusQueued   GameStatus = "queued" // Waiting for a free running slot
 	StatusRunning  GameStatus = "running"
 	StatusFinished GameStatus = "finished"
 )
@@ -42,7 +44,24 @@ type Engine struct {
 	itemRegistry    *ItemRegistry
 	recipeRegistry  *RecipeRegistry
 	handlerRegistry *HandlerRegistry
-	paused          bool // When true, tick loop doesn't run
+	actionSchema    map[string]interface{}     // Built from handlerRegistry for structured LLM output
+	paused          bool                       // When true, tick loop doesn't run
+	budgetExceeded  bool                       // Set once the LLM cost reaches config.BudgetUSD
+	lastActions     map[uuid.UUID]Action       // Last successful action per agent, for the repeat fallback
+	lastResults     map[uuid.UUID]ActionResult // Latest action result per agent, shown in the next prompt
+	lobby           *Lobby
+	createdAt       time.Time
+	finishedAt      time.Time
+	spawnPoints     []Position // Fixed spawns from an authored map, used in order
+	logger          *slog.Logger
+	history         []TickRecord
+	llmStats        llmCounters
+	stats           statsTracker
+	narrator        *narrator      // Live commentary, nil when disabled
+	recordPrompts   bool           // Keep prompts and raw responses for dataset export
+	prompts         []PromptRecord // Recorded LLM exchanges, see dataset.go
+	profiles        []TickProfile  // Rolling window of recent tick profiles
+	profileUpdates  bool           // Attach tick profiles to tick updates (dev mode)
 
 	// Biome/loot registries for per-tick resource spawning
 	biomeRegistry *worldgen.BiomeRegistry
@@ -81,7 +100,16 @@ func NewEngine(id uuid.UUID, cfg config.GameConfig, llmClient LLMClient, promptB
 }
 
 // NewEngineWithSeed creates a new game engine with procedurally generated terrain
+// using the map preset named in cfg.Map.Preset
 func NewEngineWithSeed(id uuid.UUID, cfg config.GameConfig, balance config.BalanceConfig, llmClient LLMClient, promptBuilder PromptBuilder, broadcaster Broadcaster, seed int64) *Engine {
+	mapConfig, _ := worldgen.NewMapConfigRegistry().Get(cfg.Map.Preset)
+	return NewEngineWithMapConfig(id, cfg, balance, mapConfig, llmClient, promptBuilder, broadcaster, seed)
+}
+
+// NewEngineWithMapConfig creates a new game engine whose terrain is generated
+// from the given map config (nil for the default). The map size always comes
+// from cfg so the world and spawn logic agree.
+func NewEngineWithMapConfig(id uuid.UUID, cfg config.GameConfig, balance config.BalanceConfig, baseMapConfig *worldgen.MapConfig, llmClient LLMClient, promptBuilder PromptBuilder, broadcaster Broadcaster, seed int64) *Engine {
 	var world *World
 	var enhancedTiles [][]worldgen.EnhancedTileData
 	var mapConfig *worldgen.MapConfig
@@ -92,7 +120,11 @@ func NewEngineWithSeed(id uuid.UUID, cfg config.GameConfig, balance config.Balan
 		world = NewWorld(mapSize)
 	} else {
 		// Generate procedural terrain with enhanced biome system
-		mapConfig = worldgen.DefaultMapConfig()
+		if baseMapConfig == nil {
+			baseMapConfig = worldgen.DefaultMapConfig()
+		}
+		configCopy := *baseMapConfig // Never mutate shared presets
+		mapConfig = &configCopy
 		mapConfig.CustomSize = mapSize
 		enhancedGen := worldgen.NewEnhancedWorldGenerator(seed, mapConfig)
 		enhancedTiles = enhancedGen.Generate()
@@ -114,6 +146,31 @@ func NewEngineWithSeed(id uuid.UUID, cfg config.GameConfig, balance config.Balan
 		world = NewWorldWithSeed(mapSize, seed, tiles)
 	}
 
+	engine := newEngine(id, cfg, balance, world, llmClient, promptBuilder, broadcaster, seed)
+
+	// Populate world with interactives (no initial resources — they spawn per-tick)
+	if seed != 0 && enhancedTiles != nil && mapConfig != nil {
+		populator := NewEnhancedWorldPopulator(seed, world, engine.worldObjects, mapConfig, enhancedTiles)
+		populator.PopulateInteractives()
+	} else if seed != 0 {
+		populator := NewWorldPopulator(seed, world, engine.worldObjects)
+		populator.PopulateInteractives()
+	}
+
+	return engine
+}
+
+// NewEngineWithAuthoredMap creates a game engine on a hand-authored map,
+// bypassing procedural generation. cfg's map size must match the map.
+func NewEngineWithAuthoredMap(id uuid.UUID, cfg config.GameConfig, balance config.BalanceConfig, authored *AuthoredMap, llmClient LLMClient, promptBuilder PromptBuilder, broadcaster Broadcaster, seed int64) *Engine {
+	engine := newEngine(id, cfg, balance, authored.buildWorld(seed), llmClient, promptBuilder, broadcaster, seed)
+	authored.populate(engine.worldObjects)
+	engine.spawnPoints = append([]Position(nil), authored.SpawnPoints()...)
+	return engine
+}
+
+// newEngine wires up an engine around an already built world
+func newEngine(id uuid.UUID, cfg config.GameConfig, balance config.BalanceConfig, world *World, llmClient LLMClient, promptBuilder PromptBuilder, broadcaster Broadcaster, seed int64) *Engine {
 	// Initialize registries
 	itemRegistry := DefaultItemRegistry()
 	recipeRegistry := DefaultRecipeRegistry()
@@ -124,7 +181,7 @@ func NewEngineWithSeed(id uuid.UUID, cfg config.GameConfig, balance config.Balan
 	lootTables := worldgen.NewLootTableRegistry(seed + 3000)
 	biomeLoot := worldgen.GetBiomeLootTables()
 
-	engine := &Engine{
+	return &Engine{
 		ID:              id,
 		config:          cfg,
 		balance:         balance,
@@ -145,18 +202,33 @@ func NewEngineWithSeed(id uuid.UUID, cfg config.GameConfig, balance config.Balan
 		lootTables:      lootTables,
 		biomeLoot:       biomeLoot,
 		spawnRng:        rand.New(rand.NewSource(seed + 4000)),
+		createdAt:       time.Now(),
+		logger:          logging.ForGame(id),
 	}
+}
 
-	// Populate world with interactives (no initial resources — they spawn per-tick)
-	if seed != 0 && enhancedTiles != nil && mapConfig != nil {
-		populator := NewEnhancedWorldPopulator(seed, world, worldObjects, mapConfig, enhancedTiles)
-		populator.PopulateInteractives()
-	} else if seed != 0 {
-		populator := NewWorldPopulator(seed, world, worldObjects)
-		populator.PopulateInteractives()
-	}
+// SetLobby attaches a multiplayer lobby (host and ready checks) to the game
+func (e *Engine) SetLobby(lobby *Lobby) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lobby = lobby
+}
 
-	return engine
+// GetLobby returns the game's lobby, or nil for games without a host
+func (e *Engine) GetLobby() *Lobby {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lobby
+}
+
+// GetConfig returns the game's effective configuration
+func (e *Engine) GetConfig() config.GameConfig {
+	return e.config
+}
+
+// CreatedAt returns when the game was created
+func (e *Engine) CreatedAt() time.Time {
+	return e.createdAt
 }
 
 // GetWorld returns the game world (for spawn position validation)
@@ -192,10 +264,61 @@ func (e *Engine) RemoveAgent(agentID uuid.UUID) {
 	delete(e.agents, agentID)
 }
 
+// KickAgent removes an agent from a game that has not started yet
+func (e *Engine) KickAgent(agentID uuid.UUID) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.status != StatusWaiting {
+		return ErrGameAlreadyStarted
+	}
+	if _, ok := e.agents[agentID]; !ok {
+		return ErrAgentNotFound
+	}
+
+	delete(e.agents, agentID)
+	if e.lobby != nil {
+		e.lobby.Remove(agentID)
+	}
+	return nil
+}
+
+// HasAgent checks whether an agent belongs to this game
+func (e *Engine) HasAgent(agentID uuid.UUID) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	_, ok := e.agents[agentID]
+	return ok
+}
+
+// AgentCount returns the number of agents in the game
+func (e *Engine) AgentCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.agents)
+}
+
+// AllReady checks whether every agent in the lobby has marked themselves ready.
+// Games without a lobby are always considered ready.
+func (e *Engine) AllReady() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.lobby == nil {
+		return true
+	}
+	for id := range e.agents {
+		if !e.lobby.IsReady(id) {
+			return false
+		}
+	}
+	return true
+}
+
 // Start begins the game loop (unless paused)
 func (e *Engine) Start() error {
 	e.mu.Lock()
-	if e.status != StatusWaiting {
+	if e.status != StatusWaiting && e.status != StatusQueued {
 		e.mu.Unlock()
 		return ErrGameAlreadyStarted
 	}
@@ -209,7 +332,7 @@ func (e *Engine) Start() error {
 
 	// If paused, don't start the tick loop
 	if e.paused {
-		log.Printf("Game %s started in paused mode (no tick loop)", e.ID)
+		e.logger.Info("Game started in paused mode (no tick loop)")
 		e.mu.Unlock()
 		return nil
 	}
@@ -240,7 +363,7 @@ func (e *Engine) Resume() error {
 	e.cancel = cancel
 	e.mu.Unlock()
 
-	log.Printf("Game %s resumed", e.ID)
+	e.logger.Info("Game resumed")
 	go e.runLoop(ctx)
 	return nil
 }
@@ -255,7 +378,7 @@ func (e *Engine) Pause() {
 		e.cancel = nil
 	}
 	e.paused = true
-	log.Printf("Game %s paused", e.ID)
+	e.logger.Info("Game paused")
 }
 
 // Stop ends the game
@@ -266,6 +389,9 @@ func (e *Engine) Stop() {
 	if e.cancel != nil {
 		e.cancel()
 	}
+	if e.status != StatusFinished {
+		e.finishedAt = time.Now()
+	}
 	e.status = StatusFinished
 }
 
@@ -274,12 +400,12 @@ func (e *Engine) runLoop(ctx context.Context) {
 	ticker := time.NewTicker(e.config.TickDuration)
 	defer ticker.Stop()
 
-	log.Printf("Game %s started with %d agents", e.ID, len(e.agents))
+	e.logger.Info("Game started", "agents", e.AgentCount())
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("Game %s stopped", e.ID)
+			e.logger.Info("Game stopped")
 			return
 		case <-ticker.C:
 			e.processTick(ctx)
@@ -289,10 +415,11 @@ func (e *Engine) runLoop(ctx context.Context) {
 
 // TickUpdate represents the changes in a single tick
 type TickUpdate struct {
-	Type     string         `json:"type"`
-	Tick     int            `json:"tick"`
-	Changes  TickChanges    `json:"changes"`
-	GameID   uuid.UUID      `json:"game_id"`
+	Type    string       `json:"type"`
+	Tick    int          `json:"tick"`
+	Changes TickChanges  `json:"changes"`
+	GameID  uuid.UUID    `json:"game_id"`
+	Profile *TickProfile `json:"profile,omitempty"` // Dev mode only
 }
 
 // TickChanges contains all changes from a tick
@@ -315,12 +442,27 @@ type TileChange struct {
 	OwnerID *uuid.UUID `json:"owner_id"`
 }
 
+// checkWinCondition reports whether the game should end after the given tick
+func (e *Engine) checkWinCondition(tick int) bool {
+	if e.config.WinCondition == config.WinConditionTerritory && e.config.WinThreshold > 0 {
+		for _, count := range e.world.GetOwnershipMap() {
+			if count >= e.config.WinThreshold {
+				return true
+			}
+		}
+	}
+	return e.config.WinAfterTicks > 0 && tick >= e.config.WinAfterTicks
+}
+
 // endGame finishes the game and determines winner
 func (e *Engine) endGame() {
+	stats := e.Stats()
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	e.status = StatusFinished
+	e.finishedAt = time.Now()
 	if e.cancel != nil {
 		e.cancel()
 	}
@@ -337,15 +479,22 @@ func (e *Engine) endGame() {
 		}
 	}
 
-	log.Printf("Game %s finished. Winner: %s with %d tiles", e.ID, winnerID, maxTiles)
+	e.logger.Info("Game finished", "winner", winnerID, "tiles", maxTiles)
+
+	winner := ""
+	if agent, ok := e.agents[winnerID]; ok {
+		winner = agent.Name
+	}
+	e.writeRecap(e.tick, stats, winner)
 
 	// Broadcast game end
 	if e.broadcaster != nil {
 		e.broadcaster.BroadcastToGame(e.ID, map[string]interface{}{
-			"type":     "game_over",
-			"game_id":  e.ID,
-			"winner":   winnerID,
-			"scores":   ownership,
+			"type":    "game_over",
+			"game_id": e.ID,
+			"winner":  winnerID,
+			"scores":  ownership,
+			"stats":   stats,
 		})
 	}
 }
@@ -396,7 +545,7 @@ func (e *Engine) GetFullStateForPlayer(playerAgentID uuid.UUID) FullGameState {
 	if inv := e.getPlayerInventory(playerAgentID); inv != nil {
 		state.PlayerInventory = inv.(*InventorySnapshot)
 	}
-	log.Printf("GetFullStateForPlayer: playerAgentID=%s, visibleTiles=%d", playerAgentID, len(state.VisibleTiles))
+	e.logger.Debug("Built full state for player", logging.KeyAgentID, playerAgentID, "visible_tiles", len(state.VisibleTiles))
 	return state
 }
 
@@ -422,11 +571,11 @@ func (e *Engine) getVisibleTilesForPlayer(playerAgentID uuid.UUID) []string {
 	e.mu.RUnlock()
 
 	if agent == nil {
-		log.Printf("getVisibleTilesForPlayer: agent not found for ID %s (total agents: %d)", playerAgentID, agentCount)
+		e.logger.Debug("Visible tiles requested for unknown agent", logging.KeyAgentID, playerAgentID, "agents", agentCount)
 		return nil
 	}
 	if agent.IsDead {
-		log.Printf("getVisibleTilesForPlayer: agent %s is dead", playerAgentID)
+		e.logger.Debug("Visible tiles requested for dead agent", logging.KeyAgentID, playerAgentID)
 		return nil
 	}
 
@@ -464,6 +613,10 @@ func (e *Engine) GetBalance() *config.BalanceConfig {
 // SetHandlerRegistry sets the handler registry for action processing
 func (e *Engine) SetHandlerRegistry(registry *HandlerRegistry) {
 	e.handlerRegistry = registry
+	e.actionSchema = nil
+	if registry != nil {
+		e.actionSchema = ActionSchema(registry.Types())
+	}
 }
 
 // SetPaused sets whether the game is paused (no tick loop)
@@ -512,6 +665,14 @@ var (
 	ErrGameFull           = &GameError{"game is full"}
 	ErrNoAgents           = &GameError{"no agents in game"}
 	ErrGameNotFound       = &GameError{"game not found"}
+	ErrAgentNotFound      = &GameError{"agent not found"}
+	ErrNotHost            = &GameError{"only the host can do that"}
+	ErrNotPlayer          = &GameError{"only the player can do that"}
+	ErrPlayersNotReady    = &GameError{"not all players are ready"}
+	ErrInvalidCode        = &GameError{"invalid invite code"}
+	ErrInviteRequired     = &GameError{"an invite code is required to join this game"}
+	ErrWrongPassword      = &GameError{"incorrect game password"}
+	ErrServerDraining     = &GameError{"server is draining for maintenance"}
 )
 
 // GameError represents a game-related error
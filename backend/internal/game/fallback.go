@@ -0,0 +1,115 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FallbackPolicy decides an agent's action when its LLM request fails or
+// times out
+type FallbackPolicy string
+
+const (
+	FallbackWait      FallbackPolicy = "wait"      // Do nothing this tick
+	FallbackRepeat    FallbackPolicy = "repeat"    // Repeat the agent's last successful action
+	FallbackHeuristic FallbackPolicy = "heuristic" // Ask the built-in expander bot
+	FallbackModel     FallbackPolicy = "model"     // Ask the agent's backup model
+)
+
+// heuristicBot selects the scripted expander bot (see package bots) for the
+// heuristic policy. It is reached through the LLM client, which routes the
+// bot provider to the bots.
+var heuristicBot = ModelSettings{Provider: BotProvider, Model: "expander"}
+
+// Validate checks the policy is known; empty means the server default
+func (p FallbackPolicy) Validate() error {
+	switch p {
+	case "", FallbackWait, FallbackRepeat, FallbackHeuristic, FallbackModel:
+		return nil
+	}
+	return fmt.Errorf("unknown fallback policy %q (want wait, repeat, heuristic or model)", p)
+}
+
+// fallbackPolicy returns the policy for an agent: its own, else the game's
+// configured default, else wait
+func (e *Engine) fallbackPolicy(agent *Agent) FallbackPolicy {
+	policy := agent.Model.Fallback
+	if policy == "" {
+		policy = FallbackPolicy(e.config.Fallback)
+	}
+	if policy == "" || policy.Validate() != nil {
+		return FallbackWait
+	}
+	return policy
+}
+
+// fallbackAction replaces a failed LLM request with the agent's fallback
+// policy. Policies that can't produce an action fall back to waiting.
+func (e *Engine) fallbackAction(ctx context.Context, actx AgentContext, prompt string) Action {
+	id := actx.Agent.ID
+	policy := e.fallbackPolicy(actx.Agent)
+
+	action := WaitAction(id)
+	switch policy {
+	case FallbackRepeat:
+		if last, ok := e.lastSuccessfulAction(id); ok {
+			action = last
+		}
+	case FallbackHeuristic:
+		botCtx := WithAgentContext(WithModelSettings(ctx, heuristicBot), actx)
+		if botAction, err := e.llmClient.GetAction(botCtx, id, prompt); err == nil {
+			action = botAction
+		}
+	case FallbackModel:
+		backup := actx.Agent.Model.FallbackModel
+		if backup == nil || ctx.Err() != nil {
+			break
+		}
+		var tokens []TokenUsage
+		reqCtx := WithUsageReporter(WithModelSettings(ctx, *backup), func(usage TokenUsage) {
+			tokens = append(tokens, usage)
+		})
+		start := time.Now()
+		backupAction, err := e.llmClient.GetAction(reqCtx, id, prompt)
+		latency := time.Since(start)
+		e.llmStats.record(latency, err)
+		e.stats.recordLLMRequest(id, latency, tokens, err)
+		if err == nil {
+			action = backupAction
+		}
+	}
+
+	action.AgentID = id
+	action.Fallback = policy
+	return action
+}
+
+// rememberActions keeps each agent's last successful action for the repeat
+// fallback. Messages aren't repeated.
+func (e *Engine) rememberActions(actions []Action, results []ActionResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastActions == nil {
+		e.lastActions = make(map[uuid.UUID]Action)
+	}
+	for i, action := range actions {
+		if i < len(results) && results[i].Success && action.Type != ActionMessage {
+			action.Fallback = ""
+			action.Raw = ""
+			e.lastActions[action.AgentID] = action
+		}
+	}
+}
+
+// lastSuccessfulAction returns an agent's last successful action, if any
+func (e *Engine) lastSuccessfulAction(agentID uuid.UUID) (Action, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	action, ok := e.lastActions[agentID]
+	return action, ok
+}
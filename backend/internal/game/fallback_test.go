@@ -0,0 +1,88 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// llmFunc adapts a function to LLMClient
+type llmFunc func(ctx context.Context, agentID uuid.UUID, prompt string) (Action, error)
+
+func (f llmFunc) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (Action, error) {
+	return f(ctx, agentID, prompt)
+}
+
+// staticPrompt builds the same prompt for every agent
+type staticPrompt struct{}
+
+func (staticPrompt) BuildPrompt(AgentContext) string { return "prompt" }
+
+func TestRequestActions_Fallback(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.promptBuilder = staticPrompt{}
+	engine.llmClient = llmFunc(func(ctx context.Context, agentID uuid.UUID, prompt string) (Action, error) {
+		settings, _ := ModelSettingsFromContext(ctx)
+		if settings.Model == "backup" {
+			return ClaimAction(agentID), nil
+		}
+		if settings.Provider == BotProvider { // Stands in for the bots
+			if actx, ok := AgentContextFromContext(ctx); !ok || actx.Agent.ID != agentID {
+				t.Errorf("expected the heuristic bot to get the agent context")
+			}
+			return ClaimAction(agentID), nil
+		}
+		return Action{}, errors.New("provider down")
+	})
+	engine.rememberActions(
+		[]Action{MoveAction(alice.ID, DirSouth)},
+		[]ActionResult{{AgentID: alice.ID, Action: ActionMove, Success: true}},
+	)
+
+	cases := []struct {
+		policy FallbackPolicy
+		want   ActionType
+	}{
+		{"", ActionWait},
+		{FallbackWait, ActionWait},
+		{FallbackRepeat, ActionMove},
+		{FallbackHeuristic, ActionClaim},
+		{FallbackModel, ActionClaim},
+	}
+	for _, c := range cases {
+		alice.Model = ModelSettings{Fallback: c.policy, FallbackModel: &ModelSettings{Model: "backup"}}
+		actions := engine.requestActions(context.Background(), engine.buildAgentContexts([]*Agent{alice}))
+
+		action := actions[0]
+		if action.Type != c.want || action.AgentID != alice.ID {
+			t.Errorf("policy %q: expected %s for alice, got %+v", c.policy, c.want, action)
+		}
+		want := c.policy
+		if want == "" {
+			want = FallbackWait // Server default
+		}
+		if action.Fallback != want {
+			t.Errorf("policy %q: expected action marked with fallback %q, got %q", c.policy, want, action.Fallback)
+		}
+	}
+}
+
+func TestModelSettings_ValidateFallback(t *testing.T) {
+	cases := []struct {
+		settings ModelSettings
+		ok       bool
+	}{
+		{ModelSettings{Fallback: FallbackHeuristic}, true},
+		{ModelSettings{Fallback: FallbackModel, FallbackModel: &ModelSettings{Model: "mini"}}, true},
+		{ModelSettings{Fallback: "panic"}, false},
+		{ModelSettings{Fallback: FallbackModel}, false},
+		{ModelSettings{FallbackModel: &ModelSettings{Fallback: FallbackRepeat}}, false},
+	}
+	for _, c := range cases {
+		if err := c.settings.Validate(); (err == nil) != c.ok {
+			t.Errorf("%+v: expected ok=%v, got %v", c.settings, c.ok, err)
+		}
+	}
+}
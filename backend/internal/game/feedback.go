@@ -0,0 +1,86 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rememberResults keeps each agent's latest action result so the next
+// prompt can tell the agent whether it worked and why not
+func (e *Engine) rememberResults(results []ActionResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastResults == nil {
+		e.lastResults = make(map[uuid.UUID]ActionResult)
+	}
+	for _, result := range results {
+		e.lastResults[result.AgentID] = result
+	}
+}
+
+// lastResult returns a copy of an agent's latest action result, or nil
+func (e *Engine) lastResult(agentID uuid.UUID) *ActionResult {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result, ok := e.lastResults[agentID]
+	if !ok {
+		return nil
+	}
+	return &result
+}
+
+// validateAction runs the action's handler validation against the current
+// state without applying it
+func (e *Engine) validateAction(actx AgentContext, action Action) error {
+	if e.handlerRegistry == nil {
+		return nil
+	}
+	handler, ok := e.handlerRegistry.Get(action.Type)
+	if !ok {
+		return fmt.Errorf("unknown action type")
+	}
+	ctx := NewActionContext(actx.Agent, e.world, e.worldObjects, e.itemRegistry, e.recipeRegistry, e.agents, actx.CurrentTick, action, &e.balance)
+	return handler.Validate(ctx)
+}
+
+// reask asks an agent once more in the same tick after its action failed
+// validation, showing it why. It only runs when config.Reask is on and the
+// remaining time covers another request as slow as the first. The
+// returned prompt and action replace the originals when ok is true.
+func (e *Engine) reask(ctx context.Context, actx AgentContext, action Action, latency time.Duration) (string, Action, bool) {
+	if !e.config.Reask {
+		return "", action, false
+	}
+	invalid := e.validateAction(actx, action)
+	if invalid == nil {
+		return "", action, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < latency {
+		return "", action, false
+	}
+
+	rejected := FailedResult(actx.Agent.ID, action.Type, invalid.Error())
+	actx.LastResult = &rejected
+	actx.Reask = true
+	prompt := e.promptBuilder.BuildPrompt(actx)
+
+	var tokens []TokenUsage
+	reqCtx := WithUsageReporter(WithModelSettings(WithAgentContext(ctx, actx), actx.Agent.Model), func(usage TokenUsage) {
+		tokens = append(tokens, usage)
+	})
+	start := time.Now()
+	retry, err := e.llmClient.GetAction(reqCtx, actx.Agent.ID, prompt)
+	latency = time.Since(start)
+	e.llmStats.record(latency, err)
+	e.stats.recordLLMRequest(actx.Agent.ID, latency, tokens, err)
+	e.stats.recordReask(actx.Agent.ID)
+	if err != nil {
+		return "", action, false // Keep the first answer; it fails with its own reason
+	}
+	return prompt, retry, true
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// stubHandler accepts every action of its type unless err is set
+type stubHandler struct {
+	actionType ActionType
+	err        error
+}
+
+func (h stubHandler) ActionType() ActionType { return h.actionType }
+
+func (h stubHandler) Validate(*ActionContext) error { return h.err }
+
+func (h stubHandler) Process(ctx *ActionContext) ActionResult {
+	if h.err != nil {
+		return FailedResult(ctx.Agent.ID, h.actionType, h.err.Error())
+	}
+	return SuccessResult(ctx.Agent.ID, h.actionType, "ok")
+}
+
+func TestRequestActions_Reask(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.promptBuilder = staticPrompt{}
+	registry := NewHandlerRegistry()
+	registry.Register(stubHandler{actionType: ActionFight, err: errors.New("target not adjacent")})
+	registry.Register(stubHandler{actionType: ActionClaim})
+	engine.SetHandlerRegistry(registry)
+
+	var rejected []string
+	engine.llmClient = llmFunc(func(ctx context.Context, agentID uuid.UUID, prompt string) (Action, error) {
+		if actx, _ := AgentContextFromContext(ctx); actx.Reask {
+			rejected = append(rejected, actx.LastResult.Message)
+			return ClaimAction(agentID), nil
+		}
+		return FightAction(agentID, uuid.New()), nil
+	})
+
+	contexts := engine.buildAgentContexts([]*Agent{alice})
+	if got := engine.requestActions(context.Background(), contexts)[0]; got.Type != ActionFight {
+		t.Fatalf("expected no re-ask unless enabled, got %+v", got)
+	}
+
+	engine.config.Reask = true
+	got := engine.requestActions(context.Background(), contexts)[0]
+	if got.Type != ActionClaim {
+		t.Errorf("expected the re-asked CLAIM, got %+v", got)
+	}
+	if len(rejected) != 1 || rejected[0] != "target not adjacent" {
+		t.Errorf("expected one re-ask showing the rejection, got %v", rejected)
+	}
+	if stats := engine.Stats(); stats[0].LLMReasks != 1 || stats[0].LLMRequests != 3 {
+		t.Errorf("expected 1 re-ask in 3 requests, got %+v", stats[0])
+	}
+}
+
+func TestBuildAgentContexts_LastResult(t *testing.T) {
+	engine, alice, bob := newStatsTestEngine(t)
+	if actx := engine.buildAgentContexts([]*Agent{alice})[0]; actx.LastResult != nil {
+		t.Fatalf("expected no last result before the first tick, got %+v", actx.LastResult)
+	}
+
+	engine.rememberResults([]ActionResult{
+		FailedResult(alice.ID, ActionMove, "path blocked"),
+		SuccessResult(bob.ID, ActionClaim, "claimed 3 tiles"),
+	})
+	contexts := engine.buildAgentContexts([]*Agent{alice, bob})
+	if r := contexts[0].LastResult; r == nil || r.Success || r.Message != "path blocked" {
+		t.Errorf("expected alice to see her failed move, got %+v", r)
+	}
+	if r := contexts[1].LastResult; r == nil || !r.Success || r.Action != ActionClaim {
+		t.Errorf("expected bob to see his claim, got %+v", r)
+	}
+}
@@ -0,0 +1,218 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// HighlightType identifies the kind of key moment
+type HighlightType string
+
+const (
+	HighlightKill       HighlightType = "kill"
+	HighlightCapture    HighlightType = "capture"
+	HighlightLeadChange HighlightType = "lead_change"
+	HighlightComeback   HighlightType = "comeback"
+	HighlightBetrayal   HighlightType = "betrayal"
+)
+
+// Highlight detection thresholds
+const (
+	highlightMinClaimed   = 20  // Tiles claimed in one action to count as a large capture
+	highlightMinCaptured  = 5   // Tiles taken from other agents in one action
+	comebackDeficitRatio  = 0.5 // Trailing agent held at most this share of the leader's territory
+	comebackMinLeaderSize = 10  // Leader territory needed for a deficit to count
+)
+
+// peacefulWords mark a direct message as friendly for betrayal detection
+var peacefulWords = []string{
+	"ally", "alliance", "peace", "truce", "friend", "team up", "together",
+	"cooperate", "won't attack", "will not attack", "no fighting", "don't fight",
+}
+
+// Highlight is a bookmarked tick worth jumping to in a replay
+type Highlight struct {
+	Tick        int           `json:"tick"`
+	Type        HighlightType `json:"type"`
+	AgentID     uuid.UUID     `json:"agent_id"`
+	TargetID    *uuid.UUID    `json:"target_id,omitempty"`
+	Description string        `json:"description"`
+}
+
+// Highlights detects key moments in the game's recorded history
+func (e *Engine) Highlights() []Highlight {
+	history := e.History()
+	territory := make(map[uuid.UUID][]int)
+	names := make(map[uuid.UUID]string)
+	for _, stats := range e.Stats() {
+		territory[stats.AgentID] = stats.Territory
+		names[stats.AgentID] = stats.Name
+	}
+	return DetectHighlights(history, territory, names)
+}
+
+// DetectHighlights flags kills, large captures, lead changes, comebacks and
+// betrayals. territory holds each agent's tile count after each tick; series
+// shorter than the history are aligned to its end.
+func DetectHighlights(history []TickRecord, territory map[uuid.UUID][]int, names map[uuid.UUID]string) []Highlight {
+	name := func(id uuid.UUID) string {
+		if n, ok := names[id]; ok {
+			return n
+		}
+		return id.String()
+	}
+
+	agentIDs := make([]uuid.UUID, 0, len(territory))
+	for id := range territory {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Slice(agentIDs, func(i, j int) bool { return agentIDs[i].String() < agentIDs[j].String() })
+
+	var highlights []Highlight
+	peaceful := make(map[[2]uuid.UUID]bool) // Sender and recipient of an unbroken friendly message
+	var leader uuid.UUID
+	trailed := make(map[uuid.UUID]bool) // Agents that fell far behind the leader
+
+	for i, record := range history {
+		for _, result := range record.Results {
+			if !result.Success {
+				continue
+			}
+			if result.Killed != nil && *result.Killed != result.AgentID {
+				highlights = append(highlights, Highlight{
+					Tick:        record.Tick,
+					Type:        HighlightKill,
+					AgentID:     result.AgentID,
+					TargetID:    result.Killed,
+					Description: fmt.Sprintf("%s killed %s", name(result.AgentID), name(*result.Killed)),
+				})
+			}
+
+			if h, ok := captureHighlight(record.Tick, result, name); ok {
+				highlights = append(highlights, h)
+			}
+
+			if result.Action == ActionFight && result.TargetID != nil {
+				pair := [2]uuid.UUID{result.AgentID, *result.TargetID}
+				if peaceful[pair] {
+					delete(peaceful, pair)
+					highlights = append(highlights, Highlight{
+						Tick:        record.Tick,
+						Type:        HighlightBetrayal,
+						AgentID:     result.AgentID,
+						TargetID:    result.TargetID,
+						Description: fmt.Sprintf("%s attacked %s despite promising peace", name(result.AgentID), name(*result.TargetID)),
+					})
+				}
+			}
+		}
+
+		// Messages are delivered after actions resolve, so they only count
+		// towards betrayals in later ticks
+		for _, msg := range record.Messages {
+			if msg.ToAgentID != nil && isPeaceful(msg.Content) {
+				peaceful[[2]uuid.UUID{msg.FromAgentID, *msg.ToAgentID}] = true
+			}
+		}
+
+		// Lead changes and comebacks
+		counts := make(map[uuid.UUID]int, len(agentIDs))
+		for _, id := range agentIDs {
+			series := territory[id]
+			if idx := i - (len(history) - len(series)); idx >= 0 {
+				counts[id] = series[idx]
+			}
+		}
+		current, ok := soleLeader(agentIDs, counts)
+		if ok && current != leader {
+			if leader != uuid.Nil {
+				prev := leader
+				h := Highlight{
+					Tick:        record.Tick,
+					Type:        HighlightLeadChange,
+					AgentID:     current,
+					TargetID:    &prev,
+					Description: fmt.Sprintf("%s takes the lead from %s", name(current), name(prev)),
+				}
+				if trailed[current] {
+					h.Type = HighlightComeback
+					h.Description = fmt.Sprintf("%s comes back from far behind to overtake %s", name(current), name(prev))
+				}
+				highlights = append(highlights, h)
+			}
+			leader = current
+			delete(trailed, current)
+		}
+		if leader != uuid.Nil && counts[leader] >= comebackMinLeaderSize {
+			for _, id := range agentIDs {
+				if id != leader && float64(counts[id]) <= float64(counts[leader])*comebackDeficitRatio {
+					trailed[id] = true
+				}
+			}
+		}
+	}
+
+	return highlights
+}
+
+// captureHighlight reports a large claim or capture
+func captureHighlight(tick int, result ActionResult, name func(uuid.UUID) string) (Highlight, bool) {
+	captured := 0
+	var victim uuid.UUID
+	for id, count := range result.CapturedFrom {
+		captured += count
+		victim = id
+	}
+
+	switch {
+	case captured >= highlightMinCaptured:
+		h := Highlight{
+			Tick:        tick,
+			Type:        HighlightCapture,
+			AgentID:     result.AgentID,
+			Description: fmt.Sprintf("%s captured %d tiles from rivals", name(result.AgentID), captured),
+		}
+		if len(result.CapturedFrom) == 1 {
+			h.TargetID = &victim
+			h.Description = fmt.Sprintf("%s captured %d tiles from %s", name(result.AgentID), captured, name(victim))
+		}
+		return h, true
+	case len(result.ClaimedTiles) >= highlightMinClaimed:
+		return Highlight{
+			Tick:        tick,
+			Type:        HighlightCapture,
+			AgentID:     result.AgentID,
+			Description: fmt.Sprintf("%s claimed %d tiles in one move", name(result.AgentID), len(result.ClaimedTiles)),
+		}, true
+	}
+	return Highlight{}, false
+}
+
+// soleLeader returns the agent with the most territory, if there is exactly one
+func soleLeader(agentIDs []uuid.UUID, counts map[uuid.UUID]int) (uuid.UUID, bool) {
+	var leader uuid.UUID
+	best, tied := 0, false
+	for _, id := range agentIDs {
+		switch c := counts[id]; {
+		case c > best:
+			leader, best, tied = id, c, false
+		case c == best:
+			tied = true
+		}
+	}
+	return leader, best > 0 && !tied
+}
+
+// isPeaceful reports whether a message reads as a friendly overture
+func isPeaceful(content string) bool {
+	content = strings.ToLower(content)
+	for _, word := range peacefulWords {
+		if strings.Contains(content, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDetectHighlights(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	names := map[uuid.UUID]string{alice: "Alice", bob: "Bob"}
+	territory := map[uuid.UUID][]int{
+		alice: {20, 20, 22, 10, 10},
+		bob:   {5, 5, 8, 30, 30},
+	}
+	history := []TickRecord{
+		{Tick: 1, Results: []ActionResult{
+			{AgentID: alice, Action: ActionClaim, Success: true, ClaimedTiles: make([]Position, 20)},
+		}},
+		{Tick: 2, Messages: []GameMessage{
+			{FromAgentID: bob, ToAgentID: &alice, Content: "Let's form an Alliance against the world"},
+		}},
+		{Tick: 3, Results: []ActionResult{
+			{AgentID: bob, Action: ActionClaim, Success: true, ClaimedTiles: make([]Position, 3)},
+		}},
+		{Tick: 4, Results: []ActionResult{
+			{AgentID: bob, Action: ActionFight, Success: true, TargetID: &alice, Killed: &alice},
+			{AgentID: bob, Action: ActionClaim, Success: true, ClaimedTiles: make([]Position, 12), CapturedFrom: map[uuid.UUID]int{alice: 12}},
+		}},
+		{Tick: 5, Results: []ActionResult{
+			{AgentID: bob, Action: ActionFight, Success: true, TargetID: &alice},
+		}},
+	}
+
+	highlights := DetectHighlights(history, territory, names)
+
+	want := []struct {
+		tick int
+		typ  HighlightType
+	}{
+		{1, HighlightCapture},
+		{4, HighlightKill},
+		{4, HighlightBetrayal},
+		{4, HighlightCapture},
+		{4, HighlightComeback},
+	}
+	if len(highlights) != len(want) {
+		t.Fatalf("expected %d highlights, got %+v", len(want), highlights)
+	}
+	for i, w := range want {
+		if highlights[i].Tick != w.tick || highlights[i].Type != w.typ {
+			t.Errorf("highlight %d: expected %s at tick %d, got %+v", i, w.typ, w.tick, highlights[i])
+		}
+	}
+	if got := highlights[3].Description; got != "Bob captured 12 tiles from Alice" {
+		t.Errorf("unexpected capture description %q", got)
+	}
+}
+
+func TestDetectHighlights_LeadChange(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	territory := map[uuid.UUID][]int{
+		alice: {4, 6, 6},
+		bob:   {5, 5, 6}, // Tie at the end keeps Alice in the lead
+	}
+	history := []TickRecord{{Tick: 1}, {Tick: 2}, {Tick: 3}}
+
+	highlights := DetectHighlights(history, territory, nil)
+	if len(highlights) != 1 || highlights[0].Type != HighlightLeadChange || highlights[0].Tick != 2 || highlights[0].AgentID != alice {
+		t.Errorf("expected a single lead change to Alice at tick 2, got %+v", highlights)
+	}
+}
+
+func TestIsPeaceful(t *testing.T) {
+	if !isPeaceful("TRUCE? I won't attack you") {
+		t.Error("expected truce offer to be peaceful")
+	}
+	if isPeaceful("get off my land") {
+		t.Error("expected threat not to be peaceful")
+	}
+}
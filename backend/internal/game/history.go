@@ -0,0 +1,55 @@
+package game
+
+import (
+	"sort"
+)
+
+// TickRecord is the part of a tick kept for replays, exports and analysis
+type TickRecord struct {
+	Tick     int            `json:"tick"`
+	Tiles    []TileChange   `json:"tiles"`
+	Results  []ActionResult `json:"results"`
+	Messages []GameMessage  `json:"messages"`
+}
+
+// recordTick appends a tick's changes to the game history
+func (e *Engine) recordTick(tick int, changes TickChanges) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.history = append(e.history, TickRecord{
+		Tick:     tick,
+		Tiles:    changes.Tiles,
+		Results:  changes.Results,
+		Messages: changes.Messages,
+	})
+}
+
+// History returns the recorded ticks in order. Records are shared and must
+// not be modified.
+func (e *Engine) History() []TickRecord {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	history := make([]TickRecord, len(e.history))
+	copy(history, e.history)
+	return history
+}
+
+// AgentSnapshots returns snapshots of all agents sorted by name, then ID
+func (e *Engine) AgentSnapshots() []AgentSnapshot {
+	e.mu.RLock()
+	snapshots := make([]AgentSnapshot, 0, len(e.agents))
+	for _, agent := range e.agents {
+		snapshots = append(snapshots, agent.Snapshot())
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].Name != snapshots[j].Name {
+			return snapshots[i].Name < snapshots[j].Name
+		}
+		return snapshots[i].ID.String() < snapshots[j].ID.String()
+	})
+	return snapshots
+}
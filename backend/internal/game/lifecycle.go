@@ -0,0 +1,231 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/logging"
+)
+
+// defaultReapInterval is used when no reap interval is configured
+const defaultReapInterval = 30 * time.Second
+
+// ViewerCounter is implemented by broadcasters that know how many clients
+// are watching each game. Without it, idle games are never auto-paused.
+type ViewerCounter interface {
+	GetGameClientCount(gameID uuid.UUID) int
+}
+
+// FinishedAt returns when the game finished, or the zero time
+func (e *Engine) FinishedAt() time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.finishedAt
+}
+
+// markQueued moves a waiting game to the queued state
+func (e *Engine) markQueued() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.status != StatusWaiting {
+		return ErrGameAlreadyStarted
+	}
+	if len(e.agents) == 0 {
+		return ErrNoAgents
+	}
+	e.status = StatusQueued
+	return nil
+}
+
+// startOrQueue starts a game, or queues it when the running game limit is
+// reached. Queued games are started by the reaper as slots free up.
+func (m *Manager) startOrQueue(game *Engine) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit := m.config.Lifecycle.MaxRunningGames
+	if limit > 0 && (m.runningCount() >= limit || len(m.queue) > 0) {
+		if err := game.markQueued(); err != nil {
+			return err
+		}
+		m.queue = append(m.queue, game.ID)
+		slog.Info("Game queued", logging.KeyGameID, game.ID, "queue_length", len(m.queue))
+		return nil
+	}
+
+	if err := game.Start(); err != nil {
+		return err
+	}
+	m.lastViewed[game.ID] = time.Now()
+	return nil
+}
+
+// runningCount returns the number of running games that aren't paused, so
+// games paused by hand, for having no viewers or for their budget free
+// their slot. Resuming one waits in the queue for a free slot, see resume.
+// Caller must hold m.mu.
+func (m *Manager) runningCount() int {
+	count := 0
+	for _, game := range m.games {
+		if game.GetStatus() == StatusRunning && !game.IsPaused() {
+			count++
+		}
+	}
+	return count
+}
+
+// startQueued starts queued games, and resumes paused games waiting for a
+// slot, while running slots are free. Caller must hold m.mu.
+func (m *Manager) startQueued() {
+	limit := m.config.Lifecycle.MaxRunningGames
+	for len(m.queue) > 0 && (limit <= 0 || m.runningCount() < limit) {
+		gameID := m.queue[0]
+		m.queue = m.queue[1:]
+
+		game, ok := m.games[gameID]
+		if !ok {
+			continue
+		}
+		if game.GetStatus() == StatusRunning && game.IsPaused() {
+			if err := game.Resume(); err != nil {
+				slog.Error("Failed to resume queued game", logging.KeyGameID, gameID, "error", err)
+				continue
+			}
+			m.lastViewed[gameID] = time.Now()
+			slog.Info("Game resumed from queue", logging.KeyGameID, gameID)
+			continue
+		}
+		if game.GetStatus() != StatusQueued {
+			continue
+		}
+		if err := game.Start(); err != nil {
+			slog.Error("Failed to start queued game", logging.KeyGameID, gameID, "error", err)
+			continue
+		}
+		m.lastViewed[gameID] = time.Now()
+		slog.Info("Game started from queue", logging.KeyGameID, gameID)
+	}
+}
+
+// NoteViewer records that someone is watching a game, resuming it right away
+// if the reaper paused it for being idle
+func (m *Manager) NoteViewer(gameID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return
+	}
+	m.lastViewed[gameID] = time.Now()
+	m.resumeIdle(game)
+}
+
+// resumeIdle resumes a game paused by the reaper. Caller must hold m.mu.
+func (m *Manager) resumeIdle(game *Engine) {
+	if !m.idlePaused[game.ID] {
+		return
+	}
+	delete(m.idlePaused, game.ID)
+	if err := m.resume(game); err != nil {
+		slog.Error("Failed to resume idle game", logging.KeyGameID, game.ID, "error", err)
+	}
+}
+
+// resume resumes a paused game, or queues it when the running game limit is
+// reached so resuming never runs more games than the limit. Caller must hold
+// m.mu.
+func (m *Manager) resume(game *Engine) error {
+	limit := m.config.Lifecycle.MaxRunningGames
+	if limit <= 0 || m.runningCount() < limit || game.GetStatus() != StatusRunning || !game.IsPaused() {
+		return game.Resume()
+	}
+	if !slices.Contains(m.queue, game.ID) {
+		m.queue = append(m.queue, game.ID)
+		slog.Info("Game queued to resume", logging.KeyGameID, game.ID, "queue_length", len(m.queue))
+	}
+	return nil
+}
+
+// dequeue drops a game from the queue. Caller must hold m.mu.
+func (m *Manager) dequeue(gameID uuid.UUID) {
+	m.queue = slices.DeleteFunc(m.queue, func(id uuid.UUID) bool { return id == gameID })
+}
+
+// RunReaper periodically removes expired finished games, pauses unwatched
+// games and starts queued ones until ctx is cancelled
+func (m *Manager) RunReaper(ctx context.Context) {
+	interval := m.config.Lifecycle.ReapInterval
+	if interval <= 0 {
+		interval = defaultReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.reap(now)
+		}
+	}
+}
+
+// reap runs one lifecycle pass
+func (m *Manager) reap(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lifecycle := m.config.Lifecycle
+	viewers, _ := m.hub.(ViewerCounter)
+
+	for id, game := range m.games {
+		switch game.GetStatus() {
+		case StatusFinished:
+			finishedAt := game.FinishedAt()
+			if lifecycle.FinishedTTL > 0 && !finishedAt.IsZero() && now.Sub(finishedAt) >= lifecycle.FinishedTTL {
+				m.forget(id)
+				slog.Info("Removed finished game", logging.KeyGameID, id, "ttl", lifecycle.FinishedTTL)
+			}
+
+		case StatusRunning:
+			if viewers == nil || lifecycle.IdlePauseAfter <= 0 {
+				continue
+			}
+			if viewers.GetGameClientCount(id) > 0 {
+				m.lastViewed[id] = now
+				m.resumeIdle(game)
+				continue
+			}
+			lastViewed, ok := m.lastViewed[id]
+			if !ok {
+				m.lastViewed[id] = now
+				continue
+			}
+			if !game.IsPaused() && now.Sub(lastViewed) >= lifecycle.IdlePauseAfter {
+				game.Pause()
+				m.idlePaused[id] = true
+				slog.Info("Paused game without viewers", logging.KeyGameID, id, "idle", lifecycle.IdlePauseAfter)
+			}
+		}
+	}
+
+	m.prunePlayers(now)
+	m.startQueued()
+}
+
+// forget drops a game and its lifecycle bookkeeping. Caller must hold m.mu.
+func (m *Manager) forget(gameID uuid.UUID) {
+	if game, ok := m.games[gameID]; ok {
+		m.retireUsage(game)
+	}
+	delete(m.games, gameID)
+	delete(m.lastViewed, gameID)
+	delete(m.idlePaused, gameID)
+	logging.SetGameDebug(gameID, false)
+}
@@ -0,0 +1,252 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Visibility controls who can find and join a game
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"   // Listed; anyone can join or watch
+	VisibilityUnlisted Visibility = "unlisted" // Not listed; anyone with the game ID or a code
+	VisibilityPrivate  Visibility = "private"  // Not listed; invite code to join, spectator code to watch
+)
+
+// CodeRole describes what a shared code grants
+type CodeRole string
+
+const (
+	CodeRolePlayer    CodeRole = "player"
+	CodeRoleSpectator CodeRole = "spectator"
+)
+
+// inviteCodeAlphabet omits look-alike characters (0/O, 1/I/L)
+const inviteCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// InviteCodeLength is the number of characters in invite and spectator codes
+const InviteCodeLength = 6
+
+// Lobby tracks the pre-game lifecycle of a multiplayer game: who the host is,
+// which joined players have marked themselves ready, and who may get in.
+type Lobby struct {
+	mu            sync.RWMutex
+	hostName      string
+	hostToken     string
+	playerTokens  map[uuid.UUID]string
+	ready         map[uuid.UUID]bool
+	visibility    Visibility
+	inviteCode    string
+	spectatorCode string
+	passwordSalt  []byte
+	passwordHash  []byte // nil when no password is set
+}
+
+// NewLobby creates a public lobby with a freshly generated host token and
+// invite/spectator codes
+func NewLobby(hostName string) *Lobby {
+	return &Lobby{
+		hostName:      hostName,
+		hostToken:     newToken(16),
+		playerTokens:  make(map[uuid.UUID]string),
+		ready:         make(map[uuid.UUID]bool),
+		visibility:    VisibilityPublic,
+		inviteCode:    newInviteCode(),
+		spectatorCode: newInviteCode(),
+	}
+}
+
+// SetAccess sets the lobby visibility and password (empty for none)
+func (l *Lobby) SetAccess(visibility Visibility, password string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.visibility = visibility
+	if password == "" {
+		l.passwordSalt, l.passwordHash = nil, nil
+		return
+	}
+	l.passwordSalt = randomBytes(16)
+	l.passwordHash = hashPassword(l.passwordSalt, password)
+}
+
+// Visibility returns the lobby visibility
+func (l *Lobby) Visibility() Visibility {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.visibility
+}
+
+// HasPassword returns whether joining requires a password
+func (l *Lobby) HasPassword() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.passwordHash != nil
+}
+
+// CheckPassword verifies a join password. Always true when none is set.
+func (l *Lobby) CheckPassword(password string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.passwordHash == nil {
+		return true
+	}
+	return subtle.ConstantTimeCompare(hashPassword(l.passwordSalt, password), l.passwordHash) == 1
+}
+
+// InviteCode returns the code that lets players join
+func (l *Lobby) InviteCode() string {
+	return l.inviteCode
+}
+
+// SpectatorCode returns the code that lets viewers watch but not join
+func (l *Lobby) SpectatorCode() string {
+	return l.spectatorCode
+}
+
+// CodeRole returns the role granted by a code, or "" if it doesn't match
+func (l *Lobby) CodeRole(code string) CodeRole {
+	code = normalizeInviteCode(code)
+	switch {
+	case code == "":
+		return ""
+	case subtle.ConstantTimeCompare([]byte(code), []byte(l.inviteCode)) == 1:
+		return CodeRolePlayer
+	case subtle.ConstantTimeCompare([]byte(code), []byte(l.spectatorCode)) == 1:
+		return CodeRoleSpectator
+	default:
+		return ""
+	}
+}
+
+// Listed returns whether the game shows up in the public game list
+func (l *Lobby) Listed() bool {
+	return l.Visibility() == VisibilityPublic
+}
+
+// CanJoin checks whether a player presenting code may join. Private games
+// require the invite code; spectator codes never grant a seat.
+func (l *Lobby) CanJoin(code string) bool {
+	role := l.CodeRole(code)
+	if role == CodeRoleSpectator {
+		return false
+	}
+	return l.Visibility() != VisibilityPrivate || role == CodeRolePlayer
+}
+
+// CanView checks whether a viewer presenting code may watch the game
+func (l *Lobby) CanView(code string) bool {
+	return l.Visibility() != VisibilityPrivate || l.CodeRole(code) != ""
+}
+
+// HostName returns the display name of the host
+func (l *Lobby) HostName() string {
+	return l.hostName
+}
+
+// HostToken returns the secret token that authorizes host-only actions
+func (l *Lobby) HostToken() string {
+	return l.hostToken
+}
+
+// IsHost checks a token against the host token in constant time
+func (l *Lobby) IsHost(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(l.hostToken)) == 1
+}
+
+// AddPlayer records the secret token a joined player presents for
+// player-only actions such as readying up
+func (l *Lobby) AddPlayer(agentID uuid.UUID, token string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.playerTokens[agentID] = token
+}
+
+// PlayerToken returns a joined player's token, or "" if it has none
+func (l *Lobby) PlayerToken(agentID uuid.UUID) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.playerTokens[agentID]
+}
+
+// IsPlayer checks a token against a player's token in constant time
+func (l *Lobby) IsPlayer(agentID uuid.UUID, token string) bool {
+	want := l.PlayerToken(agentID)
+	return token != "" && want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// SetReady marks a player as ready or not ready
+func (l *Lobby) SetReady(agentID uuid.UUID, ready bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ready {
+		l.ready[agentID] = true
+	} else {
+		delete(l.ready, agentID)
+	}
+}
+
+// IsReady returns whether a player has marked themselves ready
+func (l *Lobby) IsReady(agentID uuid.UUID) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.ready[agentID]
+}
+
+// Remove forgets a player's ready state and token (used when kicking)
+func (l *Lobby) Remove(agentID uuid.UUID) {
+	l.SetReady(agentID, false)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.playerTokens, agentID)
+}
+
+// hashPassword derives a salted password digest
+func hashPassword(salt []byte, password string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+	return h.Sum(nil)
+}
+
+// newInviteCode returns a short, human-friendly random code. Random bytes
+// past the last whole multiple of the alphabet size are redrawn so every
+// character is equally likely.
+func newInviteCode() string {
+	const limit = 256 - 256%len(inviteCodeAlphabet)
+	code := make([]byte, 0, InviteCodeLength)
+	for len(code) < InviteCodeLength {
+		for _, b := range randomBytes(InviteCodeLength - len(code)) {
+			if int(b) < limit {
+				code = append(code, inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)])
+			}
+		}
+	}
+	return string(code)
+}
+
+// normalizeInviteCode upper-cases a user-typed code and strips separators
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// newToken returns a random hex token of n bytes
+func newToken(n int) string {
+	return hex.EncodeToString(randomBytes(n))
+}
+
+// randomBytes returns n bytes from crypto/rand
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return b
+}
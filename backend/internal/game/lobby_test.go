@@ -0,0 +1,63 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLobbyAccess_Private(t *testing.T) {
+	lobby := NewLobby("host")
+	lobby.SetAccess(VisibilityPrivate, "hunter2")
+
+	if lobby.Listed() {
+		t.Error("private lobby should not be listed")
+	}
+	if lobby.CanJoin("") || lobby.CanView("") {
+		t.Error("private lobby should require a code")
+	}
+	if !lobby.CanJoin(strings.ToLower(lobby.InviteCode())) {
+		t.Error("invite code should allow joining regardless of case")
+	}
+	if lobby.CanJoin(lobby.SpectatorCode()) {
+		t.Error("spectator code must not allow joining")
+	}
+	if !lobby.CanView(lobby.SpectatorCode()) {
+		t.Error("spectator code should allow viewing")
+	}
+	if lobby.CheckPassword("wrong") || !lobby.CheckPassword("hunter2") {
+		t.Error("password check failed")
+	}
+}
+
+func TestLobbyAccess_Public(t *testing.T) {
+	lobby := NewLobby("host")
+
+	if !lobby.Listed() || !lobby.CanJoin("") || !lobby.CanView("") {
+		t.Error("public lobby should be listed, joinable and viewable")
+	}
+	if !lobby.CheckPassword("") {
+		t.Error("lobby without password should accept any password")
+	}
+	if lobby.CanJoin(lobby.SpectatorCode()) {
+		t.Error("spectator code must not allow joining even public games")
+	}
+}
+
+func TestNewInviteCode(t *testing.T) {
+	seen := make(map[rune]bool)
+	for i := 0; i < 200; i++ {
+		code := newInviteCode()
+		if len(code) != InviteCodeLength {
+			t.Fatalf("expected %d characters, got %q", InviteCodeLength, code)
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(inviteCodeAlphabet, c) {
+				t.Fatalf("unexpected character %q in %q", c, code)
+			}
+			seen[c] = true
+		}
+	}
+	if len(seen) != len(inviteCodeAlphabet) {
+		t.Errorf("expected every alphabet character to appear, got %d of %d", len(seen), len(inviteCodeAlphabet))
+	}
+}
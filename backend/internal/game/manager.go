@@ -1,7 +1,9 @@
 package game
 
 import (
+	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,7 +25,19 @@ type Manager struct {
 	postgres        *db.Postgres
 	redis           *db.Redis
 	handlerRegistry *HandlerRegistry
-	pauseByDefault  bool // When true, new games start paused
+	pauseByDefault  bool          // When true, new games start paused
+	profileUpdates  bool          // When true, tick updates carry phase timings
+	narrator        TextGenerator // Live commentary for new games, nil when disabled
+	draining        bool          // When true, no new games are created or joined
+
+	// Lifecycle bookkeeping, see lifecycle.go
+	queue      []uuid.UUID             // Games waiting for a running slot, in order
+	lastViewed map[uuid.UUID]time.Time // Last time a running game had viewers
+	idlePaused map[uuid.UUID]bool      // Games paused by the reaper for having no viewers
+
+	// Players by ID, see players.go
+	playersMu sync.Mutex
+	players   map[uuid.UUID]*player
 }
 
 // NewManager creates a new game manager
@@ -43,6 +57,9 @@ func NewManagerWithBalance(cfg config.GameConfig, balance config.BalanceConfig,
 		postgres:        postgres,
 		redis:           redis,
 		handlerRegistry: nil, // Set via SetHandlerRegistry
+		lastViewed:      make(map[uuid.UUID]time.Time),
+		idlePaused:      make(map[uuid.UUID]bool),
+		players:         make(map[uuid.UUID]*player),
 	}
 }
 
@@ -60,31 +77,66 @@ func (m *Manager) SetPauseByDefault(paused bool) {
 	m.pauseByDefault = paused
 }
 
-// PauseGame pauses a running game
-func (m *Manager) PauseGame(gameID uuid.UUID) error {
+// SetDraining toggles maintenance drain mode. While draining, running games
+// continue but new games can't be created or joined.
+func (m *Manager) SetDraining(draining bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = draining
+}
+
+// IsDraining reports whether the server is draining
+func (m *Manager) IsDraining() bool {
 	m.mu.RLock()
-	game, ok := m.games[gameID]
-	m.mu.RUnlock()
+	defer m.mu.RUnlock()
+	return m.draining
+}
 
+// SetProfileUpdates controls whether new games attach tick profiles to
+// their tick updates (dev only)
+func (m *Manager) SetProfileUpdates(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profileUpdates = enabled
+}
+
+// SetNarrator enables live commentary for new games, using the configured
+// narrator timeout. A nil gen disables it.
+func (m *Manager) SetNarrator(gen TextGenerator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.narrator = gen
+}
+
+// PauseGame pauses a running game, letting a queued game take its slot
+func (m *Manager) PauseGame(gameID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[gameID]
 	if !ok {
 		return ErrGameNotFound
 	}
 
 	game.Pause()
+	m.dequeue(gameID) // Don't resume it later if it was waiting for a slot
+	m.startQueued()
 	return nil
 }
 
-// ResumeGame resumes a paused game
+// ResumeGame resumes a paused game. When no running slot is free it stays
+// paused in the queue until one frees up.
 func (m *Manager) ResumeGame(gameID uuid.UUID) error {
-	m.mu.RLock()
-	game, ok := m.games[gameID]
-	m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	game, ok := m.games[gameID]
 	if !ok {
 		return ErrGameNotFound
 	}
 
-	return game.Resume()
+	delete(m.idlePaused, gameID) // Resumed by hand; the reaper no longer owns the pause
+	return m.resume(game)
 }
 
 // CreateGame creates a new game instance with optional seed
@@ -95,17 +147,51 @@ func (m *Manager) CreateGame() (*Engine, error) {
 // CreateGameWithSeed creates a new game instance with a specific seed
 // If seed is 0, a random seed will be generated
 func (m *Manager) CreateGameWithSeed(seed int64) (*Engine, error) {
+	return m.CreateGameWithSettings(GameSettings{Seed: seed}, "")
+}
+
+// CreateGameWithSettings creates a multiplayer game with per-game overrides of
+// the server config. A lobby is attached with hostName as its host.
+func (m *Manager) CreateGameWithSettings(settings GameSettings, hostName string) (*Engine, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.draining {
+		return nil, ErrServerDraining
+	}
+
+	settings, err := m.withConfiguredMap(settings)
+	if err != nil {
+		return nil, err
+	}
+	cfg, balance, err := settings.Apply(m.config, m.balance)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate random seed if not provided
+	seed := cfg.Map.Seed
 	if seed == 0 {
 		seed = time.Now().UnixNano()
 	}
 
+	if hostName == "" {
+		hostName = "Host"
+	}
+
 	gameID := uuid.New()
-	engine := NewEngineWithSeed(gameID, m.config, m.balance, m.llmClient, m.promptBuilder, m.hub, seed)
+	engine := m.newGameEngine(gameID, settings, cfg, balance, seed)
 	engine.SetHandlerRegistry(m.handlerRegistry)
+	lobby := NewLobby(hostName)
+	for m.codeInUse(lobby.inviteCode) || m.codeInUse(lobby.spectatorCode) || lobby.inviteCode == lobby.spectatorCode {
+		lobby.inviteCode, lobby.spectatorCode = newInviteCode(), newInviteCode()
+	}
+	visibility := settings.Visibility
+	if visibility == "" {
+		visibility = VisibilityPublic
+	}
+	lobby.SetAccess(visibility, settings.Password)
+	engine.SetLobby(lobby)
 	if m.pauseByDefault {
 		engine.SetPaused(true)
 	}
@@ -114,6 +200,63 @@ func (m *Manager) CreateGameWithSeed(seed int64) (*Engine, error) {
 	return engine, nil
 }
 
+// withConfiguredMap loads the server's configured authored map (map.file)
+// into settings that don't specify a map of their own
+func (m *Manager) withConfiguredMap(settings GameSettings) (GameSettings, error) {
+	if m.config.Map.File == "" || settings.AuthoredMap != nil || settings.MapConfig != nil ||
+		settings.MapPreset != "" || settings.MapSize != "" || settings.CustomSize != 0 {
+		return settings, nil
+	}
+	authored, err := LoadAuthoredMap(m.config.Map.File)
+	if err != nil {
+		return settings, fmt.Errorf("loading map file: %w", err)
+	}
+	settings.AuthoredMap = authored
+	return settings, nil
+}
+
+// newGameEngine creates an engine from an authored map or procedural terrain
+func (m *Manager) newGameEngine(gameID uuid.UUID, settings GameSettings, cfg config.GameConfig, balance config.BalanceConfig, seed int64) *Engine {
+	var engine *Engine
+	if settings.AuthoredMap != nil {
+		engine = NewEngineWithAuthoredMap(gameID, cfg, balance, settings.AuthoredMap, m.llmClient, m.promptBuilder, m.hub, seed)
+	} else {
+		engine = NewEngineWithMapConfig(gameID, cfg, balance, settings.baseMapConfig(cfg), m.llmClient, m.promptBuilder, m.hub, seed)
+	}
+	engine.SetProfileUpdates(m.profileUpdates)
+	engine.SetRecordPrompts(cfg.RecordPrompts)
+	if m.narrator != nil {
+		engine.SetNarrator(m.narrator, cfg.Narrator.Timeout)
+	}
+	return engine
+}
+
+// codeInUse checks whether any game already uses an invite or spectator code.
+// Caller must hold m.mu.
+func (m *Manager) codeInUse(code string) bool {
+	for _, engine := range m.games {
+		if lobby := engine.GetLobby(); lobby != nil && lobby.CodeRole(code) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// FindGameByCode resolves an invite or spectator code to its game
+func (m *Manager) FindGameByCode(code string) (*Engine, CodeRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, engine := range m.games {
+		if lobby := engine.GetLobby(); lobby != nil {
+			if role := lobby.CodeRole(code); role != "" {
+				return engine, role, nil
+			}
+		}
+	}
+	return nil, "", ErrInvalidCode
+}
+
 // CreateSingleplayerGame creates a game with AI adversaries
 func (m *Manager) CreateSingleplayerGame(playerPrompt string, adversaryTypes []string) (*Engine, uuid.UUID, error) {
 	return m.CreateSingleplayerGameWithSeed(playerPrompt, adversaryTypes, 0, "")
@@ -123,39 +266,76 @@ func (m *Manager) CreateSingleplayerGame(playerPrompt string, adversaryTypes []s
 // If seed is 0, a random seed will be generated.
 // If mapSizeOverride is non-empty, it overrides the config's map size (e.g. "tiny", "small", "medium", "large", "huge", "massive").
 func (m *Manager) CreateSingleplayerGameWithSeed(playerPrompt string, adversaryTypes []string, seed int64, mapSizeOverride string) (*Engine, uuid.UUID, error) {
+	return m.CreateSingleplayerGameWithSettings(playerPrompt, adversaryTypes, GameSettings{Seed: seed, MapSize: mapSizeOverride})
+}
+
+// CreateSingleplayerGameWithSettings creates a singleplayer game with
+// per-game overrides such as map preset, size or a custom map config
+func (m *Manager) CreateSingleplayerGameWithSettings(playerPrompt string, adversaryTypes []string, settings GameSettings) (*Engine, uuid.UUID, error) {
+	adversaries := make([]AdversarySpec, len(adversaryTypes))
+	for i, advType := range adversaryTypes {
+		adversaries[i] = AdversarySpec{Type: advType}
+	}
+	return m.CreateSingleplayerGameWithModels(playerPrompt, ModelSettings{}, adversaries, settings)
+}
+
+// CreateSingleplayerGameWithModels creates a singleplayer game where the
+// player and each adversary can use their own model and sampling settings
+func (m *Manager) CreateSingleplayerGameWithModels(playerPrompt string, playerModel ModelSettings, adversaries []AdversarySpec, settings GameSettings) (*Engine, uuid.UUID, error) {
+	if err := m.validateModel(playerModel); err != nil {
+		return nil, uuid.Nil, err
+	}
+	for _, adv := range adversaries {
+		if err := m.validateModel(adv.Model); err != nil {
+			return nil, uuid.Nil, fmt.Errorf("adversary %s: %w", adv.Type, err)
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.draining {
+		return nil, uuid.Nil, ErrServerDraining
+	}
+
+	settings, err := m.withConfiguredMap(settings)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	cfg, balance, err := settings.Apply(m.config, m.balance)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
 	// Generate random seed if not provided
+	seed := cfg.Map.Seed
 	if seed == 0 {
 		seed = time.Now().UnixNano()
 	}
 
-	// Apply map size override from the request if provided
-	cfg := m.config
-	if mapSizeOverride != "" {
-		cfg.Map.Size = mapSizeOverride
-		cfg.MapSize = 0 // Clear direct map_size so Map.Size takes effect
-	}
-
 	gameID := uuid.New()
-	engine := NewEngineWithSeed(gameID, cfg, m.balance, m.llmClient, m.promptBuilder, m.hub, seed)
+	engine := m.newGameEngine(gameID, settings, cfg, balance, seed)
 	engine.SetHandlerRegistry(m.handlerRegistry)
 	if m.pauseByDefault {
 		engine.SetPaused(true)
 	}
 
-	// Generate spawn positions with passability check
-	positions := generateSpawnPositionsForWorld(engine.GetWorld(), len(adversaryTypes)+1)
+	// Use the map's fixed spawns first, then generate the rest with passability check
+	positions := engine.spawnPoints
+	if extra := len(adversaries) + 1 - len(positions); extra > 0 {
+		positions = append(positions, generateSpawnPositionsForWorld(engine.GetWorld(), extra)...)
+	}
 
 	// Add player agent
-	playerAgent := NewAgentWithBalance(gameID, "Player", playerPrompt, positions[0], m.config.MaxMemoryItems, &m.balance)
+	playerAgent := NewAgentWithBalance(gameID, "Player", playerPrompt, positions[0], cfg.MaxMemoryItems, engine.GetBalance())
+	playerAgent.Model = playerModel
 	playerAgent.InitInventory(engine.itemRegistry)
 	engine.agents[playerAgent.ID] = playerAgent
 
 	// Add adversary agents
-	for i, advType := range adversaryTypes {
-		adversary := NewAdversaryAgentWithBalance(gameID, advType, positions[i+1], m.config.MaxMemoryItems, &m.balance)
+	for i, adv := range adversaries {
+		adversary := NewAdversaryAgentWithBalance(gameID, adv.Type, positions[i+1], cfg.MaxMemoryItems, engine.GetBalance())
+		adversary.Model = adv.Model
 		adversary.InitInventory(engine.itemRegistry)
 		engine.agents[adversary.ID] = adversary
 	}
@@ -177,46 +357,177 @@ func (m *Manager) GetGame(gameID uuid.UUID) (*Engine, error) {
 	return game, nil
 }
 
-// ListGames returns all active games
-func (m *Manager) ListGames() []*GameInfo {
+// Games returns every game, including private and unlisted ones, newest first
+func (m *Manager) Games() []*Engine {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	engines := make([]*Engine, 0, len(m.games))
+	for _, engine := range m.games {
+		engines = append(engines, engine)
+	}
+	m.mu.RUnlock()
 
-	games := make([]*GameInfo, 0, len(m.games))
-	for id, engine := range m.games {
-		games = append(games, &GameInfo{
-			ID:          id,
-			Status:      engine.GetStatus(),
-			PlayerCount: len(engine.agents),
-			MaxPlayers:  m.config.MaxPlayers,
-			Tick:        engine.GetTick(),
-		})
+	sort.Slice(engines, func(i, j int) bool {
+		return engines[i].CreatedAt().After(engines[j].CreatedAt())
+	})
+	return engines
+}
+
+// GameFilter narrows and paginates the game list
+type GameFilter struct {
+	Status GameStatus // Empty matches any status
+	Open   bool       // Only waiting games with free player slots
+	Limit  int        // 0 = no limit
+	Offset int
+}
+
+// ListGames returns active games matching the filter, newest first, along
+// with the total number of matches before pagination
+func (m *Manager) ListGames(filter GameFilter) ([]*GameInfo, int) {
+	engines := m.Games()
+
+	games := make([]*GameInfo, 0, len(engines))
+	for _, engine := range engines {
+		if lobby := engine.GetLobby(); lobby != nil && !lobby.Listed() {
+			continue
+		}
+		info := engine.Info()
+		if filter.Status != "" && info.Status != filter.Status {
+			continue
+		}
+		if filter.Open && (info.Status != StatusWaiting || info.PlayerCount >= info.MaxPlayers) {
+			continue
+		}
+		games = append(games, info)
+	}
+
+	total := len(games)
+	if filter.Offset > 0 {
+		if filter.Offset >= len(games) {
+			return []*GameInfo{}, total
+		}
+		games = games[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(games) {
+		games = games[:filter.Limit]
 	}
-	return games
+	return games, total
 }
 
-// JoinGame adds a player agent to an existing game
-func (m *Manager) JoinGame(gameID uuid.UUID, playerName, systemPrompt string) (*Agent, error) {
+// JoinGame adds a player agent to an existing game. Private games require
+// the invite code, and password-protected games the password.
+func (m *Manager) JoinGame(gameID uuid.UUID, playerName, systemPrompt, inviteCode, password string) (*Agent, error) {
+	return m.JoinGameWithModel(gameID, uuid.Nil, playerName, systemPrompt, inviteCode, password, ModelSettings{})
+}
+
+// JoinGameWithModel adds a player agent that uses its own model and
+// sampling settings. The agent belongs to playerID, or to a newly
+// registered player if it is uuid.Nil.
+func (m *Manager) JoinGameWithModel(gameID, playerID uuid.UUID, playerName, systemPrompt, inviteCode, password string, model ModelSettings) (*Agent, error) {
+	if err := m.validateModel(model); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	game, ok := m.games[gameID]
+	draining := m.draining
 	m.mu.RUnlock()
 
 	if !ok {
 		return nil, ErrGameNotFound
 	}
+	if draining {
+		return nil, ErrServerDraining
+	}
+
+	if lobby := game.GetLobby(); lobby != nil {
+		if !lobby.CanJoin(inviteCode) {
+			return nil, ErrInviteRequired
+		}
+		if !lobby.CheckPassword(password) {
+			return nil, ErrWrongPassword
+		}
+	}
 
 	// Find available spawn position
-	pos := findAvailableSpawnPosition(game, m.config.GetMapSize())
+	cfg := game.GetConfig()
+	pos := findAvailableSpawnPosition(game, cfg.GetMapSize())
 
-	agent := NewAgentWithBalance(gameID, playerName, systemPrompt, pos, m.config.MaxMemoryItems, &m.balance)
+	agent := NewAgentWithBalance(gameID, playerName, systemPrompt, pos, cfg.MaxMemoryItems, game.GetBalance())
+	agent.Model = model
+	playerID = m.joinPlayer(playerID)
+	agent.SetPlayerID(playerID)
 	if err := game.AddAgent(agent); err != nil {
 		return nil, err
 	}
+	if lobby := game.GetLobby(); lobby != nil {
+		lobby.AddPlayer(agent.ID, m.playerToken(playerID))
+	}
 
 	return agent, nil
 }
 
-// StartGame starts a game
+// SetReady marks a joined player as ready (or not) in the game's lobby.
+// Only the player, presenting its token, may change its ready state.
+func (m *Manager) SetReady(gameID, agentID uuid.UUID, playerToken string, ready bool) error {
+	game, err := m.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+
+	lobby := game.GetLobby()
+	if lobby == nil {
+		return &GameError{"game has no lobby"}
+	}
+	if game.GetStatus() != StatusWaiting {
+		return ErrGameAlreadyStarted
+	}
+	if !game.HasAgent(agentID) {
+		return ErrAgentNotFound
+	}
+	if !lobby.IsPlayer(agentID, playerToken) {
+		return ErrNotPlayer
+	}
+
+	lobby.SetReady(agentID, ready)
+	return nil
+}
+
+// KickPlayer removes a player from a waiting game. Only the host may kick.
+func (m *Manager) KickPlayer(gameID uuid.UUID, hostToken string, agentID uuid.UUID) error {
+	game, err := m.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+
+	lobby := game.GetLobby()
+	if lobby == nil || !lobby.IsHost(hostToken) {
+		return ErrNotHost
+	}
+
+	return game.KickAgent(agentID)
+}
+
+// StartGameAsHost starts a lobby game once every player is ready.
+// Games without a lobby can be started by anyone.
+func (m *Manager) StartGameAsHost(gameID uuid.UUID, hostToken string) error {
+	game, err := m.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+
+	if lobby := game.GetLobby(); lobby != nil {
+		if !lobby.IsHost(hostToken) {
+			return ErrNotHost
+		}
+		if !game.AllReady() {
+			return ErrPlayersNotReady
+		}
+	}
+
+	return m.startOrQueue(game)
+}
+
+// StartGame starts a game, or queues it when too many games are running
 func (m *Manager) StartGame(gameID uuid.UUID) error {
 	m.mu.RLock()
 	game, ok := m.games[gameID]
@@ -226,7 +537,7 @@ func (m *Manager) StartGame(gameID uuid.UUID) error {
 		return ErrGameNotFound
 	}
 
-	return game.Start()
+	return m.startOrQueue(game)
 }
 
 // StopGame stops a game
@@ -240,6 +551,10 @@ func (m *Manager) StopGame(gameID uuid.UUID) error {
 	}
 
 	game.Stop()
+
+	m.mu.Lock()
+	m.startQueued()
+	m.mu.Unlock()
 	return nil
 }
 
@@ -253,11 +568,16 @@ func (m *Manager) StopAll() {
 	}
 }
 
-// RemoveGame removes a finished game
+// RemoveGame stops a game if it is still running and removes it
 func (m *Manager) RemoveGame(gameID uuid.UUID) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.games, gameID)
+
+	if game, ok := m.games[gameID]; ok {
+		game.Stop()
+		m.forget(gameID)
+		m.startQueued()
+	}
 }
 
 // ForceTick triggers a tick for a specific game (dev only)
@@ -276,11 +596,49 @@ func (m *Manager) ForceTick(gameID uuid.UUID) error {
 
 // GameInfo contains summary information about a game
 type GameInfo struct {
-	ID          uuid.UUID  `json:"id"`
-	Status      GameStatus `json:"status"`
-	PlayerCount int        `json:"player_count"`
-	MaxPlayers  int        `json:"max_players"`
-	Tick        int        `json:"tick"`
+	ID           uuid.UUID  `json:"id"`
+	Status       GameStatus `json:"status"`
+	PlayerCount  int        `json:"player_count"`
+	MaxPlayers   int        `json:"max_players"`
+	ReadyCount   int        `json:"ready_count"`
+	Tick         int        `json:"tick"`
+	Host         string     `json:"host,omitempty"`
+	MapSize      int        `json:"map_size"`
+	TickSeconds  float64    `json:"tick_seconds"`
+	WinCondition string     `json:"win_condition,omitempty"`
+	Visibility   Visibility `json:"visibility"`
+	HasPassword  bool       `json:"has_password"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Info returns summary information about the game
+func (e *Engine) Info() *GameInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	info := &GameInfo{
+		ID:           e.ID,
+		Status:       e.status,
+		PlayerCount:  len(e.agents),
+		MaxPlayers:   e.config.MaxPlayers,
+		Tick:         e.tick,
+		MapSize:      e.world.Size(),
+		TickSeconds:  e.config.TickDuration.Seconds(),
+		WinCondition: e.config.WinCondition,
+		Visibility:   VisibilityPublic,
+		CreatedAt:    e.createdAt,
+	}
+	if e.lobby != nil {
+		info.Host = e.lobby.HostName()
+		info.Visibility = e.lobby.Visibility()
+		info.HasPassword = e.lobby.HasPassword()
+		for id := range e.agents {
+			if e.lobby.IsReady(id) {
+				info.ReadyCount++
+			}
+		}
+	}
+	return info
 }
 
 // generateSpawnPositions creates evenly distributed spawn positions (legacy, no terrain check)
@@ -454,6 +812,13 @@ func findAvailableSpawnPosition(game *Engine, mapSize int) Position {
 
 	world := game.GetWorld()
 
+	// Fixed spawns from an authored map come first
+	for _, pos := range game.spawnPoints {
+		if !occupied[pos] {
+			return pos
+		}
+	}
+
 	// Try preset positions first
 	presets := generateSpawnPositions(mapSize, 8)
 	for _, pos := range presets {
@@ -0,0 +1,207 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+)
+
+func newTestManager() *Manager {
+	return NewManager(config.Default().Game, nil, nil, nil, nil, nil)
+}
+
+// viewerBroadcaster is a Broadcaster that reports a fixed viewer count
+type viewerBroadcaster struct {
+	viewers map[uuid.UUID]int
+}
+
+func (b *viewerBroadcaster) BroadcastToGame(uuid.UUID, interface{}) {}
+
+func (b *viewerBroadcaster) BroadcastToGameWithVisibility(uuid.UUID, interface{}, func(uuid.UUID) []string, func(uuid.UUID) interface{}) {
+}
+
+func (b *viewerBroadcaster) GetGameClientCount(gameID uuid.UUID) int {
+	return b.viewers[gameID]
+}
+
+// newJoinedGame creates a small game with one player
+func newJoinedGame(t *testing.T, m *Manager) *Engine {
+	t.Helper()
+	engine, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	if _, err := m.JoinGame(engine.ID, "Alice", "", "", ""); err != nil {
+		t.Fatalf("failed to join game: %v", err)
+	}
+	return engine
+}
+
+func TestManagerDraining(t *testing.T) {
+	m := newTestManager()
+	engine, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+
+	m.SetDraining(true)
+	if _, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host"); err != ErrServerDraining {
+		t.Errorf("expected ErrServerDraining creating a game, got %v", err)
+	}
+	if _, err := m.JoinGame(engine.ID, "Alice", "", "", ""); err != ErrServerDraining {
+		t.Errorf("expected ErrServerDraining joining a game, got %v", err)
+	}
+
+	m.SetDraining(false)
+	if _, err := m.JoinGame(engine.ID, "Alice", "", "", ""); err != nil {
+		t.Errorf("expected join to succeed after draining ends, got %v", err)
+	}
+}
+
+func TestManagerGames_IncludesPrivate(t *testing.T) {
+	m := newTestManager()
+	engine, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1, Visibility: VisibilityPrivate}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+
+	if games, _ := m.ListGames(GameFilter{}); len(games) != 0 {
+		t.Errorf("private game should not be listed publicly, got %d games", len(games))
+	}
+	if games := m.Games(); len(games) != 1 || games[0] != engine {
+		t.Errorf("expected Games to include the private game, got %d games", len(games))
+	}
+
+	m.RemoveGame(engine.ID)
+	if engine.GetStatus() != StatusFinished {
+		t.Errorf("expected removed game to be stopped, got %s", engine.GetStatus())
+	}
+	if len(m.Games()) != 0 {
+		t.Error("expected game to be removed")
+	}
+}
+
+func TestManagerQueue_MaxRunningGames(t *testing.T) {
+	cfg := config.Default().Game
+	cfg.Lifecycle.MaxRunningGames = 1
+	m := NewManager(cfg, nil, nil, nil, nil, nil)
+
+	first, second := newJoinedGame(t, m), newJoinedGame(t, m)
+	defer m.StopAll()
+
+	if err := m.StartGame(first.ID); err != nil {
+		t.Fatalf("failed to start first game: %v", err)
+	}
+	if err := m.StartGame(second.ID); err != nil {
+		t.Fatalf("failed to queue second game: %v", err)
+	}
+	if second.GetStatus() != StatusQueued {
+		t.Fatalf("expected second game to be queued, got %s", second.GetStatus())
+	}
+
+	if err := m.StopGame(first.ID); err != nil {
+		t.Fatalf("failed to stop first game: %v", err)
+	}
+	if second.GetStatus() != StatusRunning {
+		t.Errorf("expected queued game to start once a slot freed up, got %s", second.GetStatus())
+	}
+}
+
+func TestManagerQueue_PausedGamesFreeSlots(t *testing.T) {
+	cfg := config.Default().Game
+	cfg.Lifecycle.MaxRunningGames = 1
+	cfg.Lifecycle.IdlePauseAfter = time.Minute
+	hub := &viewerBroadcaster{viewers: make(map[uuid.UUID]int)}
+	m := NewManager(cfg, nil, nil, hub, nil, nil)
+
+	idle, queued := newJoinedGame(t, m), newJoinedGame(t, m)
+	defer m.StopAll()
+	if err := m.StartGame(idle.ID); err != nil {
+		t.Fatalf("failed to start game: %v", err)
+	}
+	if err := m.StartGame(queued.ID); err != nil || queued.GetStatus() != StatusQueued {
+		t.Fatalf("expected second game to be queued, got %s, %v", queued.GetStatus(), err)
+	}
+
+	m.reap(time.Now().Add(2 * time.Minute))
+	if !idle.IsPaused() {
+		t.Fatal("expected unwatched game to be paused")
+	}
+	if queued.GetStatus() != StatusRunning {
+		t.Errorf("expected queued game to take the idle game's slot, got %s", queued.GetStatus())
+	}
+
+	if err := m.ResumeGame(idle.ID); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if m.idlePaused[idle.ID] {
+		t.Error("expected manual resume to clear the idle pause")
+	}
+	if !idle.IsPaused() || m.runningCount() != 1 {
+		t.Errorf("expected the resumed game to wait for a slot, got %d running games", m.runningCount())
+	}
+
+	if err := m.PauseGame(queued.ID); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if idle.IsPaused() || m.runningCount() != 1 {
+		t.Errorf("expected the waiting game to resume in the freed slot, got %d running games", m.runningCount())
+	}
+}
+
+func TestManagerReap(t *testing.T) {
+	cfg := config.Default().Game
+	cfg.Lifecycle.FinishedTTL = time.Minute
+	cfg.Lifecycle.IdlePauseAfter = time.Minute
+	hub := &viewerBroadcaster{viewers: make(map[uuid.UUID]int)}
+	m := NewManager(cfg, nil, nil, hub, nil, nil)
+
+	finished, idle := newJoinedGame(t, m), newJoinedGame(t, m)
+	defer m.StopAll()
+	for _, engine := range []*Engine{finished, idle} {
+		if err := m.StartGame(engine.ID); err != nil {
+			t.Fatalf("failed to start game: %v", err)
+		}
+	}
+	finished.Stop()
+
+	later := time.Now().Add(2 * time.Minute)
+	m.reap(later)
+
+	if _, err := m.GetGame(finished.ID); err != ErrGameNotFound {
+		t.Error("expected finished game to be removed after its TTL")
+	}
+	if !idle.IsPaused() {
+		t.Error("expected unwatched game to be paused")
+	}
+
+	hub.viewers[idle.ID] = 1
+	m.reap(later.Add(time.Second))
+	if idle.IsPaused() {
+		t.Error("expected idle game to resume once someone is watching")
+	}
+}
+
+func TestManagerSetReady_RequiresPlayerToken(t *testing.T) {
+	m := NewManager(config.Default().Game, nil, nil, nil, nil, nil)
+	engine, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	lobby := engine.GetLobby()
+	alice, err := m.JoinGame(engine.ID, "Alice", "", "", "")
+	if err != nil {
+		t.Fatalf("failed to join: %v", err)
+	}
+
+	for _, token := range []string{"", lobby.HostToken(), "guess"} {
+		if err := m.SetReady(engine.ID, alice.ID, token, true); err != ErrNotPlayer {
+			t.Errorf("token %q: expected ErrNotPlayer, got %v", token, err)
+		}
+	}
+	if err := m.SetReady(engine.ID, alice.ID, lobby.PlayerToken(alice.ID), true); err != nil || !lobby.IsReady(alice.ID) {
+		t.Errorf("expected the player's own token to ready up, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"github.com/lucas/promptlands/internal/metrics"
+)
+
+var (
+	tickDuration = metrics.Default.NewHistogram("promptlands_tick_duration_seconds",
+		"Time to process a game tick.", nil)
+	tickPhaseDuration = metrics.Default.NewHistogram("promptlands_tick_phase_duration_seconds",
+		"Time spent in each phase of a game tick.", nil, "phase")
+)
+
+// RegisterMetrics exposes active game and agent counts on r
+func (m *Manager) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("promptlands_games_active", "Games currently running.", func() float64 {
+		return float64(m.countGames(func(e *Engine) int { return 1 }))
+	})
+	r.NewGaugeFunc("promptlands_games_queued", "Games waiting for a running slot.", func() float64 {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return float64(len(m.queue))
+	})
+	r.NewGaugeFunc("promptlands_agents_active", "Agents in running games.", func() float64 {
+		return float64(m.countGames((*Engine).AgentCount))
+	})
+}
+
+// countGames sums count over running games
+func (m *Manager) countGames(count func(*Engine) int) int {
+	total := 0
+	for _, game := range m.Games() {
+		if game.GetStatus() == StatusRunning {
+			total += count(game)
+		}
+	}
+	return total
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Limits for per-agent sampling parameters
+const (
+	MaxTemperature    = 2.0
+	MaxOutputTokens   = 8192
+	maxModelNameLen   = 128
+	maxProviderLength = 32
+)
+
+// BotProvider selects the scripted bots of package bots, which read the
+// agent context instead of the prompt
+const BotProvider = "bot"
+
+// ModelSettings selects the LLM backend and sampling parameters for one
+// agent. Zero values use the server's llm config.
+type ModelSettings struct {
+	Provider    string   `json:"provider,omitempty"` // gemini, openai, ollama or anthropic
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+
+	Fallback      FallbackPolicy `json:"fallback,omitempty"`       // When a request fails; empty uses the server default
+	FallbackModel *ModelSettings `json:"fallback_model,omitempty"` // Backup model for the model fallback policy
+}
+
+// IsZero reports whether no setting is overridden
+func (s ModelSettings) IsZero() bool {
+	return s.Provider == "" && s.Model == "" && s.Temperature == nil && s.TopP == nil && s.MaxTokens == 0 &&
+		s.Fallback == "" && s.FallbackModel == nil
+}
+
+// Label returns a short "provider/model" description for spectators
+func (s ModelSettings) Label() string {
+	switch {
+	case s.Provider != "" && s.Model != "":
+		return s.Provider + "/" + s.Model
+	case s.Model != "":
+		return s.Model
+	default:
+		return s.Provider
+	}
+}
+
+// Validate checks the sampling parameters are in range
+func (s ModelSettings) Validate() error {
+	if len(s.Provider) > maxProviderLength || len(s.Model) > maxModelNameLen {
+		return fmt.Errorf("model provider or name is too long")
+	}
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > MaxTemperature) {
+		return fmt.Errorf("temperature must be between 0 and %g", MaxTemperature)
+	}
+	if s.TopP != nil && (*s.TopP <= 0 || *s.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1")
+	}
+	if s.MaxTokens < 0 || s.MaxTokens > MaxOutputTokens {
+		return fmt.Errorf("max_tokens must be between 1 and %d", MaxOutputTokens)
+	}
+	if err := s.Fallback.Validate(); err != nil {
+		return err
+	}
+	if s.Fallback == FallbackModel && s.FallbackModel == nil {
+		return fmt.Errorf("fallback policy model needs a fallback_model")
+	}
+	if backup := s.FallbackModel; backup != nil {
+		if backup.Fallback != "" || backup.FallbackModel != nil {
+			return fmt.Errorf("fallback_model can't have its own fallback")
+		}
+		if err := backup.Validate(); err != nil {
+			return fmt.Errorf("fallback_model: %w", err)
+		}
+	}
+	return nil
+}
+
+// ModelValidator is implemented by LLM clients that can reject model
+// settings they can't serve, e.g. an unknown provider
+type ModelValidator interface {
+	ValidateModel(settings ModelSettings) error
+}
+
+type modelSettingsKey struct{}
+
+// WithModelSettings attaches an agent's model settings to a request context
+func WithModelSettings(ctx context.Context, settings ModelSettings) context.Context {
+	return context.WithValue(ctx, modelSettingsKey{}, settings)
+}
+
+// ModelSettingsFromContext returns the model settings attached to ctx, if any
+func ModelSettingsFromContext(ctx context.Context) (ModelSettings, bool) {
+	settings, ok := ctx.Value(modelSettingsKey{}).(ModelSettings)
+	return settings, ok
+}
+
+type gameIDKey struct{}
+
+// WithGameID attaches the requesting game's ID to an LLM request context so
+// shared schedulers can share capacity fairly between games
+func WithGameID(ctx context.Context, gameID uuid.UUID) context.Context {
+	return context.WithValue(ctx, gameIDKey{}, gameID)
+}
+
+// GameIDFromContext returns the game ID attached to ctx, or uuid.Nil
+func GameIDFromContext(ctx context.Context) uuid.UUID {
+	gameID, _ := ctx.Value(gameIDKey{}).(uuid.UUID)
+	return gameID
+}
+
+// AdversarySpec picks an adversary personality and, optionally, the model
+// that plays it. It decodes from either a type name or an object.
+type AdversarySpec struct {
+	Type  string        `json:"type"`
+	Model ModelSettings `json:"model"`
+}
+
+// UnmarshalJSON accepts "aggressive" as well as {"type": "aggressive", "model": {...}}
+func (a *AdversarySpec) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*a = AdversarySpec{Type: name}
+		return nil
+	}
+
+	type spec AdversarySpec // Avoid recursing into this method
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("adversary must be a type name or an object: %w", err)
+	}
+	*a = AdversarySpec(s)
+	return nil
+}
+
+// validateModel checks model settings against the limits and, when it can
+// tell, against what the LLM client supports
+func (m *Manager) validateModel(settings ModelSettings) error {
+	if settings.IsZero() {
+		return nil
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	validator, ok := m.llmClient.(ModelValidator)
+	if !ok {
+		return nil
+	}
+	if err := validator.ValidateModel(settings); err != nil {
+		return err
+	}
+	if settings.FallbackModel != nil {
+		if err := validator.ValidateModel(*settings.FallbackModel); err != nil {
+			return fmt.Errorf("fallback_model: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestAdversarySpec_UnmarshalJSON(t *testing.T) {
+	var specs []AdversarySpec
+	data := `["aggressive", {"type": "builder", "model": {"provider": "openai", "model": "gpt-4o-mini", "temperature": 0.3}}]`
+	if err := json.Unmarshal([]byte(data), &specs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(specs) != 2 || specs[0].Type != "aggressive" || !specs[0].Model.IsZero() {
+		t.Fatalf("expected a plain aggressive adversary first, got %+v", specs)
+	}
+	model := specs[1].Model
+	if specs[1].Type != "builder" || model.Label() != "openai/gpt-4o-mini" || model.Temperature == nil || *model.Temperature != 0.3 {
+		t.Errorf("unexpected builder spec %+v", specs[1])
+	}
+
+	if err := json.Unmarshal([]byte(`[42]`), &specs); err == nil {
+		t.Error("expected a number to be rejected")
+	}
+}
+
+func TestModelSettings_Validate(t *testing.T) {
+	hot, zero := 2.5, 0.0
+	cases := []struct {
+		settings ModelSettings
+		valid    bool
+	}{
+		{ModelSettings{}, true},
+		{ModelSettings{Temperature: &zero, MaxTokens: 512}, true},
+		{ModelSettings{Temperature: &hot}, false},
+		{ModelSettings{TopP: &zero}, false},
+		{ModelSettings{MaxTokens: MaxOutputTokens + 1}, false},
+	}
+	for _, c := range cases {
+		if err := c.settings.Validate(); (err == nil) != c.valid {
+			t.Errorf("%+v: expected valid=%v, got %v", c.settings, c.valid, err)
+		}
+	}
+}
+
+func TestModelSettingsFromContext(t *testing.T) {
+	if _, ok := ModelSettingsFromContext(context.Background()); ok {
+		t.Error("expected no settings on a bare context")
+	}
+	ctx := WithModelSettings(context.Background(), ModelSettings{Model: "m"})
+	if settings, ok := ModelSettingsFromContext(ctx); !ok || settings.Model != "m" {
+		t.Errorf("expected settings to round-trip, got %+v", settings)
+	}
+}
@@ -0,0 +1,308 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TextGenerator produces free-form text from a prompt. LLM clients that
+// implement it can back the narrator.
+type TextGenerator interface {
+	GenerateText(ctx context.Context, prompt string) (string, error)
+}
+
+// RecapPromptMarker starts every end-of-game recap prompt so generators can
+// tell recaps from play-by-play requests
+const RecapPromptMarker = "GAME RECAP"
+
+// Narrator limits
+const (
+	DefaultNarratorTimeout = 5 * time.Second
+	narratorHistory        = 50  // Commentary lines kept per game
+	narratorContextLines   = 5   // Recent lines shown to the narrator to avoid repetition
+	narratorMaxEvents      = 8   // Events described per tick
+	narratorMaxLineLength  = 280 // Longer commentary is truncated
+	narratorMaxQuote       = 120 // Longer agent messages are truncated in prompts
+)
+
+// Commentary is one narrator line
+type Commentary struct {
+	Tick int    `json:"tick"`
+	Text string `json:"text"`
+}
+
+// narrator turns tick changes into spectator commentary. Requests run in
+// the background so a slow generator never delays the tick loop; ticks that
+// arrive while a request is in flight are skipped.
+type narrator struct {
+	gen     TextGenerator
+	timeout time.Duration
+	busy    atomic.Bool
+
+	mu    sync.Mutex
+	lines []Commentary
+	recap string
+}
+
+// SetNarrator enables live commentary backed by gen. A zero timeout uses
+// DefaultNarratorTimeout; a nil gen disables commentary.
+func (e *Engine) SetNarrator(gen TextGenerator, timeout time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if gen == nil {
+		e.narrator = nil
+		return
+	}
+	if timeout <= 0 {
+		timeout = DefaultNarratorTimeout
+	}
+	e.narrator = &narrator{gen: gen, timeout: timeout}
+}
+
+// Commentary returns the commentary so far and the recap, if written
+func (e *Engine) Commentary() ([]Commentary, string) {
+	e.mu.RLock()
+	n := e.narrator
+	e.mu.RUnlock()
+	if n == nil {
+		return nil, ""
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	lines := make([]Commentary, len(n.lines))
+	copy(lines, n.lines)
+	return lines, n.recap
+}
+
+// narrate requests a commentary line for a tick in the background
+func (e *Engine) narrate(ctx context.Context, tick int, changes TickChanges) {
+	e.mu.RLock()
+	n := e.narrator
+	e.mu.RUnlock()
+	if n == nil {
+		return
+	}
+
+	events := describeTick(changes)
+	if len(events) == 0 || !n.busy.CompareAndSwap(false, true) {
+		return
+	}
+	prompt := n.tickPrompt(tick, standings(changes.Agents, e.world.GetOwnershipMap()), events)
+
+	go func() {
+		defer n.busy.Store(false)
+
+		ctx, cancel := context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+
+		text, err := n.gen.GenerateText(ctx, prompt)
+		text = cleanCommentary(text)
+		if err != nil || text == "" {
+			e.logger.Debug("Narrator produced no commentary", "tick", tick, "error", err)
+			return
+		}
+
+		n.mu.Lock()
+		n.lines = append(n.lines, Commentary{Tick: tick, Text: text})
+		if len(n.lines) > narratorHistory {
+			n.lines = n.lines[len(n.lines)-narratorHistory:]
+		}
+		n.mu.Unlock()
+
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastToGame(e.ID, map[string]interface{}{
+				"type":    "commentary",
+				"game_id": e.ID,
+				"tick":    tick,
+				"text":    text,
+			})
+		}
+	}()
+}
+
+// writeRecap asks the narrator for an end-of-game recap in the background
+// and broadcasts it. Caller must hold e.mu.
+func (e *Engine) writeRecap(tick int, stats []AgentStats, winner string) {
+	n := e.narrator
+	if n == nil {
+		return
+	}
+
+	prompt := n.recapPrompt(tick, stats, winner)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*n.timeout)
+		defer cancel()
+
+		text, err := n.gen.GenerateText(ctx, prompt)
+		text = strings.TrimSpace(text)
+		if err != nil || text == "" {
+			e.logger.Warn("Narrator failed to write recap", "error", err)
+			return
+		}
+
+		n.mu.Lock()
+		n.recap = text
+		n.mu.Unlock()
+
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastToGame(e.ID, map[string]interface{}{
+				"type":    "recap",
+				"game_id": e.ID,
+				"tick":    tick,
+				"text":    text,
+			})
+		}
+	}()
+}
+
+// tickPrompt builds the play-by-play prompt for one tick
+func (n *narrator) tickPrompt(tick int, standings string, events []string) string {
+	var b strings.Builder
+	b.WriteString("You are the live commentator for Promptlands, a territory-control game played by AI agents.\n")
+	fmt.Fprintf(&b, "Write ONE short, lively play-by-play line (at most 25 words) about tick %d for spectators. Reply with the line only.\n\n", tick)
+	fmt.Fprintf(&b, "Standings: %s\n\nEvents this tick:\n", standings)
+	for _, event := range events {
+		b.WriteString("- " + event + "\n")
+	}
+
+	n.mu.Lock()
+	recent := n.lines[max(0, len(n.lines)-narratorContextLines):]
+	if len(recent) > 0 {
+		b.WriteString("\nYour recent lines (don't repeat yourself):\n")
+		for _, line := range recent {
+			b.WriteString("- " + line.Text + "\n")
+		}
+	}
+	n.mu.Unlock()
+	return b.String()
+}
+
+// recapPrompt builds the end-of-game recap prompt from the final stats and
+// the commentary so far
+func (n *narrator) recapPrompt(tick int, stats []AgentStats, winner string) string {
+	var b strings.Builder
+	b.WriteString(RecapPromptMarker + "\n")
+	fmt.Fprintf(&b, "You are the commentator for Promptlands, a territory-control game played by AI agents. The game just ended after %d ticks", tick)
+	if winner != "" {
+		fmt.Fprintf(&b, " and %s won", winner)
+	}
+	b.WriteString(".\nWrite a short narrative recap (3-5 sentences) of how the game unfolded. Reply with the recap only.\n\nFinal stats:\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "- %s: %d tiles (peak %d), captured %d, lost %d, %d kills, %d deaths\n",
+			s.Name, s.FinalTerritory, s.PeakTerritory, s.TilesCaptured, s.TilesLost, s.Kills, s.Deaths)
+	}
+
+	n.mu.Lock()
+	if len(n.lines) > 0 {
+		b.WriteString("\nYour commentary during the game:\n")
+		for _, line := range n.lines {
+			fmt.Fprintf(&b, "- Tick %d: %s\n", line.Tick, line.Text)
+		}
+	}
+	n.mu.Unlock()
+	return b.String()
+}
+
+// describeTick lists the notable events of a tick in plain language
+func describeTick(changes TickChanges) []string {
+	names := make(map[uuid.UUID]string, len(changes.Agents))
+	for _, agent := range changes.Agents {
+		names[agent.ID] = agent.Name
+	}
+	name := func(id uuid.UUID) string {
+		if n, ok := names[id]; ok {
+			return n
+		}
+		return "an unknown agent"
+	}
+
+	var events []string
+	for _, result := range changes.Results {
+		if !result.Success {
+			continue
+		}
+		if result.Killed != nil {
+			if *result.Killed == result.AgentID {
+				events = append(events, fmt.Sprintf("%s was %s", name(result.AgentID), result.Message))
+			} else {
+				events = append(events, fmt.Sprintf("%s killed %s", name(result.AgentID), name(*result.Killed)))
+			}
+		}
+
+		captured := 0
+		for victim, count := range result.CapturedFrom {
+			captured += count
+			events = append(events, fmt.Sprintf("%s captured %d tiles from %s", name(result.AgentID), count, name(victim)))
+		}
+		if claimed := len(result.ClaimedTiles) - captured; claimed > 0 {
+			events = append(events, fmt.Sprintf("%s claimed %d neutral tiles", name(result.AgentID), claimed))
+		}
+	}
+
+	for _, msg := range changes.Messages {
+		content := truncateText(msg.Content, narratorMaxQuote)
+		if msg.ToAgentID != nil {
+			events = append(events, fmt.Sprintf("%s told %s: %q", name(msg.FromAgentID), name(*msg.ToAgentID), content))
+		} else {
+			events = append(events, fmt.Sprintf("%s announced: %q", name(msg.FromAgentID), content))
+		}
+	}
+
+	for _, id := range changes.Respawned {
+		events = append(events, fmt.Sprintf("%s respawned", name(id)))
+	}
+
+	if len(events) > narratorMaxEvents {
+		events = events[:narratorMaxEvents]
+	}
+	return events
+}
+
+// standings formats agents by territory, largest first
+func standings(agents []AgentSnapshot, territory map[uuid.UUID]int) string {
+	sorted := make([]AgentSnapshot, len(agents))
+	copy(sorted, agents)
+	sort.Slice(sorted, func(i, j int) bool {
+		if territory[sorted[i].ID] != territory[sorted[j].ID] {
+			return territory[sorted[i].ID] > territory[sorted[j].ID]
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	parts := make([]string, len(sorted))
+	for i, agent := range sorted {
+		parts[i] = fmt.Sprintf("%s %d tiles", agent.Name, territory[agent.ID])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cleanCommentary keeps the first non-empty line of a reply, unquoted and
+// truncated
+func cleanCommentary(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), `"`)
+		if line == "" {
+			continue
+		}
+		return truncateText(line, narratorMaxLineLength)
+	}
+	return ""
+}
+
+// truncateText shortens text to at most n runes, marking the cut
+func truncateText(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeGenerator returns a fixed reply and records prompts
+type fakeGenerator struct {
+	mu      sync.Mutex
+	reply   string
+	prompts []string
+}
+
+func (g *fakeGenerator) GenerateText(ctx context.Context, prompt string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prompts = append(g.prompts, prompt)
+	return g.reply, nil
+}
+
+func TestDescribeTick(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	changes := TickChanges{
+		Agents: []AgentSnapshot{{ID: alice, Name: "Alice"}, {ID: bob, Name: "Bob"}},
+		Results: []ActionResult{
+			{AgentID: alice, Success: true, ClaimedTiles: make([]Position, 5), CapturedFrom: map[uuid.UUID]int{bob: 2}},
+			{AgentID: bob, Success: true, Killed: &alice},
+			{AgentID: bob, Success: false, Message: "tile is blocked"},
+		},
+		Messages:  []GameMessage{{FromAgentID: bob, Content: "truce?"}},
+		Respawned: []uuid.UUID{alice},
+	}
+
+	got := strings.Join(describeTick(changes), "\n")
+	want := strings.Join([]string{
+		"Alice captured 2 tiles from Bob",
+		"Alice claimed 3 neutral tiles",
+		"Bob killed Alice",
+		`Bob announced: "truce?"`,
+		"Alice respawned",
+	}, "\n")
+	if got != want {
+		t.Errorf("unexpected events:\n%s\nwant:\n%s", got, want)
+	}
+
+	if events := describeTick(TickChanges{Agents: changes.Agents}); len(events) != 0 {
+		t.Errorf("expected a quiet tick to have no events, got %v", events)
+	}
+}
+
+func TestNarrate(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	gen := &fakeGenerator{reply: "\n\"Alice storms ahead!\"\nextra line"}
+	engine.SetNarrator(gen, time.Second)
+
+	changes := TickChanges{
+		Agents:  []AgentSnapshot{alice.Snapshot()},
+		Results: []ActionResult{{AgentID: alice.ID, Success: true, ClaimedTiles: make([]Position, 4)}},
+	}
+	engine.narrate(context.Background(), 3, changes)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		lines, _ := engine.Commentary()
+		if len(lines) == 1 {
+			if lines[0].Tick != 3 || lines[0].Text != "Alice storms ahead!" {
+				t.Errorf("unexpected commentary %+v", lines[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for commentary")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !strings.Contains(gen.prompts[0], "Alice claimed 4 neutral tiles") {
+		t.Errorf("expected prompt to describe the claim, got %q", gen.prompts[0])
+	}
+}
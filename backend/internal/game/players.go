@@ -0,0 +1,128 @@
+package game
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// playerRetention is how long a player without agents in any live game is
+// kept, with its usage totals, before it is forgotten
+const playerRetention = 24 * time.Hour
+
+// player is someone joining games. Its secret token, issued on its first
+// join, identifies it in later joins and when reading its usage.
+type player struct {
+	token    string
+	retired  Usage     // Usage from the player's games that have since been removed
+	lastSeen time.Time // Last join, or removal of one of its games
+}
+
+// joinPlayer returns the player joining a game, registering a new one when
+// playerID is uuid.Nil
+func (m *Manager) joinPlayer(playerID uuid.UUID) uuid.UUID {
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	if p, ok := m.players[playerID]; ok {
+		p.lastSeen = time.Now()
+		return playerID
+	}
+	if playerID == uuid.Nil {
+		playerID = uuid.New()
+	}
+	m.players[playerID] = &player{token: newToken(16), lastSeen: time.Now()}
+	return playerID
+}
+
+// playerToken returns a player's secret token, or "" for unknown players
+func (m *Manager) playerToken(playerID uuid.UUID) string {
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	if p, ok := m.players[playerID]; ok {
+		return p.token
+	}
+	return ""
+}
+
+// IsPlayer checks a token against a player's token in constant time
+func (m *Manager) IsPlayer(playerID uuid.UUID, token string) bool {
+	want := m.playerToken(playerID)
+	return token != "" && want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// PlayerUsage returns a player's LLM usage summed over every game it joined,
+// including games that have been removed since. Requires the player's token.
+// Totals live in memory and start over when the server restarts.
+func (m *Manager) PlayerUsage(playerID uuid.UUID, token string) (Usage, error) {
+	if !m.IsPlayer(playerID, token) {
+		return Usage{}, ErrNotPlayer
+	}
+
+	// Holding m.mu keeps games from being retired while they are summed
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var total Usage
+	for _, engine := range m.games {
+		for _, p := range engine.Usage().Players {
+			if p.PlayerID == playerID {
+				total = total.Add(p.Usage)
+			}
+		}
+	}
+
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	p, ok := m.players[playerID]
+	if !ok {
+		return Usage{}, ErrNotPlayer // Forgotten since the token was checked
+	}
+	return total.Add(p.retired), nil
+}
+
+// retireUsage keeps a removed game's usage in its players' totals. Caller
+// must hold m.mu.
+func (m *Manager) retireUsage(engine *Engine) {
+	players := engine.Usage().Players
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	for _, usage := range players {
+		if p, ok := m.players[usage.PlayerID]; ok {
+			p.retired = p.retired.Add(usage.Usage)
+			p.lastSeen = time.Now()
+		}
+	}
+}
+
+// prunePlayers forgets players that have had no agent in a live game for
+// playerRetention. Caller must hold m.mu.
+func (m *Manager) prunePlayers(now time.Time) {
+	live := make(map[uuid.UUID]bool)
+	for _, engine := range m.games {
+		for _, id := range engine.playerIDs() {
+			live[id] = true
+		}
+	}
+
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	for id, p := range m.players {
+		if !live[id] && now.Sub(p.lastSeen) >= playerRetention {
+			delete(m.players, id)
+		}
+	}
+}
+
+// playerIDs returns the players controlling the game's agents
+func (e *Engine) playerIDs() []uuid.UUID {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	ids := make([]uuid.UUID, 0, len(e.agents))
+	for _, agent := range e.agents {
+		if agent.PlayerID != nil {
+			ids = append(ids, *agent.PlayerID)
+		}
+	}
+	return ids
+}
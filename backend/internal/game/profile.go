@@ -0,0 +1,146 @@
+package game
+
+import (
+	"time"
+)
+
+// profileWindow is the number of recent tick profiles kept per game
+const profileWindow = 100
+
+// PhaseTiming is the time spent in one tick phase and how many items
+// (agents, actions, objects, ...) it handled
+type PhaseTiming struct {
+	Phase      string  `json:"phase"`
+	DurationMs float64 `json:"duration_ms"`
+	Count      int     `json:"count"`
+}
+
+// TickProfile breaks a tick's processing time down by phase
+type TickProfile struct {
+	Tick      int           `json:"tick"`
+	StartedAt time.Time     `json:"started_at"`
+	TotalMs   float64       `json:"total_ms"`
+	Phases    []PhaseTiming `json:"phases"`
+}
+
+// PhaseSummary aggregates a phase over the profile window
+type PhaseSummary struct {
+	Phase      string  `json:"phase"`
+	AvgMs      float64 `json:"avg_ms"`
+	MaxMs      float64 `json:"max_ms"`
+	AvgCount   float64 `json:"avg_count"`
+	ShareOfAvg float64 `json:"share"` // Fraction of the average tick spent in this phase
+}
+
+// phaseTimer times consecutive phases of a tick, feeding both the metrics
+// histograms and the tick's profile
+type phaseTimer struct {
+	last    time.Time
+	profile TickProfile
+}
+
+func newPhaseTimer(tick int) *phaseTimer {
+	now := time.Now()
+	return &phaseTimer{last: now, profile: TickProfile{Tick: tick, StartedAt: now}}
+}
+
+// done records the time since the previous phase ended
+func (p *phaseTimer) done(phase string, count int) {
+	now := time.Now()
+	elapsed := now.Sub(p.last)
+	p.last = now
+
+	tickPhaseDuration.Observe(elapsed.Seconds(), phase)
+	p.profile.Phases = append(p.profile.Phases, PhaseTiming{
+		Phase:      phase,
+		DurationMs: durationMs(elapsed),
+		Count:      count,
+	})
+}
+
+// snapshot returns the profile so far
+func (p *phaseTimer) snapshot() *TickProfile {
+	profile := p.profile
+	profile.Phases = append([]PhaseTiming(nil), p.profile.Phases...)
+	profile.TotalMs = durationMs(p.last.Sub(profile.StartedAt))
+	return &profile
+}
+
+// finish records the whole tick and returns its profile
+func (p *phaseTimer) finish() TickProfile {
+	elapsed := time.Since(p.profile.StartedAt)
+	tickDuration.Observe(elapsed.Seconds())
+	p.profile.TotalMs = durationMs(elapsed)
+	return p.profile
+}
+
+// recordProfile adds a tick profile to the rolling window
+func (e *Engine) recordProfile(profile TickProfile) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.profiles = append(e.profiles, profile)
+	if len(e.profiles) > profileWindow {
+		e.profiles = append(e.profiles[:0:0], e.profiles[len(e.profiles)-profileWindow:]...)
+	}
+}
+
+// Profiles returns the most recent tick profiles, oldest first
+func (e *Engine) Profiles() []TickProfile {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	profiles := make([]TickProfile, len(e.profiles))
+	copy(profiles, e.profiles)
+	return profiles
+}
+
+// SetProfileUpdates controls whether tick updates carry the tick's profile
+func (e *Engine) SetProfileUpdates(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.profileUpdates = enabled
+}
+
+// SummarizeProfiles averages each phase over the given profiles, in phase order
+func SummarizeProfiles(profiles []TickProfile) []PhaseSummary {
+	if len(profiles) == 0 {
+		return []PhaseSummary{}
+	}
+
+	var order []string
+	byPhase := make(map[string]*PhaseSummary)
+	var totalMs float64
+	for _, profile := range profiles {
+		totalMs += profile.TotalMs
+		for _, timing := range profile.Phases {
+			summary, ok := byPhase[timing.Phase]
+			if !ok {
+				summary = &PhaseSummary{Phase: timing.Phase}
+				byPhase[timing.Phase] = summary
+				order = append(order, timing.Phase)
+			}
+			summary.AvgMs += timing.DurationMs
+			summary.AvgCount += float64(timing.Count)
+			summary.MaxMs = max(summary.MaxMs, timing.DurationMs)
+		}
+	}
+
+	n := float64(len(profiles))
+	summaries := make([]PhaseSummary, len(order))
+	for i, phase := range order {
+		summary := byPhase[phase]
+		if totalMs > 0 {
+			summary.ShareOfAvg = summary.AvgMs / totalMs
+		}
+		summary.AvgMs /= n
+		summary.AvgCount /= n
+		summaries[i] = *summary
+	}
+	return summaries
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
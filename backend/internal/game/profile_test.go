@@ -0,0 +1,49 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarizeProfiles(t *testing.T) {
+	profiles := []TickProfile{
+		{Tick: 1, TotalMs: 10, Phases: []PhaseTiming{
+			{Phase: "llm", DurationMs: 8, Count: 2},
+			{Phase: "process", DurationMs: 2, Count: 4},
+		}},
+		{Tick: 2, TotalMs: 30, Phases: []PhaseTiming{
+			{Phase: "llm", DurationMs: 24, Count: 2},
+			{Phase: "process", DurationMs: 6, Count: 6},
+		}},
+	}
+
+	summary := SummarizeProfiles(profiles)
+	if len(summary) != 2 || summary[0].Phase != "llm" || summary[1].Phase != "process" {
+		t.Fatalf("expected llm and process phases in order, got %+v", summary)
+	}
+	llm := summary[0]
+	if llm.AvgMs != 16 || llm.MaxMs != 24 || llm.AvgCount != 2 {
+		t.Errorf("unexpected llm summary %+v", llm)
+	}
+	if math.Abs(llm.ShareOfAvg-0.8) > 1e-9 {
+		t.Errorf("expected llm to take 80%% of tick time, got %v", llm.ShareOfAvg)
+	}
+	if summary[1].AvgCount != 5 {
+		t.Errorf("expected average process count 5, got %v", summary[1].AvgCount)
+	}
+}
+
+func TestRecordProfile_RollingWindow(t *testing.T) {
+	e := &Engine{}
+	for tick := 1; tick <= profileWindow+5; tick++ {
+		e.recordProfile(TickProfile{Tick: tick})
+	}
+
+	profiles := e.Profiles()
+	if len(profiles) != profileWindow {
+		t.Fatalf("expected %d profiles, got %d", profileWindow, len(profiles))
+	}
+	if profiles[0].Tick != 6 || profiles[len(profiles)-1].Tick != profileWindow+5 {
+		t.Errorf("expected ticks 6..%d, got %d..%d", profileWindow+5, profiles[0].Tick, profiles[len(profiles)-1].Tick)
+	}
+}
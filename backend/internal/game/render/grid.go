@@ -0,0 +1,228 @@
+// Package render draws game maps as PNG images and territory timelapses as
+// animated GIFs using only the standard library image packages.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"strconv"
+	"strings"
+)
+
+// Output size limits
+const (
+	DefaultMaxDim = 512  // Longest side of the output image in pixels
+	MaxDim        = 4096 // Upper bound accepted from callers
+	maxCellPixels = 16   // Largest upscale factor for small maps
+	ownerAlpha    = 0.6  // Opacity of the territory overlay
+)
+
+// OwnerPalette holds the territory colors assigned to agents in order
+var OwnerPalette = []color.RGBA{
+	{R: 0xE6, G: 0x19, B: 0x4B, A: 0xFF}, // red
+	{R: 0x43, G: 0x63, B: 0xD8, A: 0xFF}, // blue
+	{R: 0xFF, G: 0xE1, B: 0x19, A: 0xFF}, // yellow
+	{R: 0x3C, G: 0xB4, B: 0x4B, A: 0xFF}, // green
+	{R: 0xF5, G: 0x82, B: 0x31, A: 0xFF}, // orange
+	{R: 0x91, G: 0x1E, B: 0xB4, A: 0xFF}, // purple
+	{R: 0x42, G: 0xD4, B: 0xF4, A: 0xFF}, // cyan
+	{R: 0xF0, G: 0x32, B: 0xE6, A: 0xFF}, // magenta
+	{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, // white
+	{R: 0x80, G: 0x00, B: 0x00, A: 0xFF}, // maroon
+	{R: 0x00, G: 0x00, B: 0x75, A: 0xFF}, // navy
+	{R: 0xAA, G: 0xFF, B: 0xC3, A: 0xFF}, // mint
+	{R: 0x80, G: 0x80, B: 0x00, A: 0xFF}, // olive
+	{R: 0xFA, G: 0xBE, B: 0xD4, A: 0xFF}, // pink
+	{R: 0x00, G: 0x00, B: 0x00, A: 0xFF}, // black
+	{R: 0x9A, G: 0x63, B: 0x24, A: 0xFF}, // brown
+}
+
+// Grid is a square map of base tile colors with an optional owner per tile
+type Grid struct {
+	Size   int
+	Base   []color.RGBA // Size*Size tile colors, row-major
+	Owners []int        // Size*Size indexes into OwnerPalette; -1 for unowned
+}
+
+// NewGrid creates an unowned grid of the given size
+func NewGrid(size int) *Grid {
+	g := &Grid{
+		Size:   size,
+		Base:   make([]color.RGBA, size*size),
+		Owners: make([]int, size*size),
+	}
+	for i := range g.Owners {
+		g.Owners[i] = -1
+	}
+	return g
+}
+
+// SetOwner sets the owner index of a tile, ignoring out-of-bounds positions
+func (g *Grid) SetOwner(x, y, owner int) {
+	if x < 0 || y < 0 || x >= g.Size || y >= g.Size {
+		return
+	}
+	g.Owners[y*g.Size+x] = owner
+}
+
+// ParseHexColor parses "#RRGGBB" into an opaque color
+func ParseHexColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+}
+
+// ownerColor returns the palette color for an owner index
+func ownerColor(owner int) color.RGBA {
+	return OwnerPalette[owner%len(OwnerPalette)]
+}
+
+// blend overlays an owner color on a base color
+func blend(base, over color.RGBA) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a)*(1-ownerAlpha) + float64(b)*ownerAlpha + 0.5)
+	}
+	return color.RGBA{R: mix(base.R, over.R), G: mix(base.G, over.G), B: mix(base.B, over.B), A: 0xFF}
+}
+
+// layout maps tiles to pixels. Large maps are downsampled so each pixel
+// covers block x block tiles; small maps are upscaled so each tile covers
+// cell x cell pixels.
+type layout struct {
+	size  int
+	block int // Tiles per pixel along each axis
+	cell  int // Pixels per tile along each axis
+	dim   int // Output width and height in pixels
+}
+
+// newLayout picks the scale that fits size tiles into maxDim pixels
+func newLayout(size, maxDim int) layout {
+	if maxDim <= 0 {
+		maxDim = DefaultMaxDim
+	}
+	if maxDim > MaxDim {
+		maxDim = MaxDim
+	}
+
+	l := layout{size: size, block: 1, cell: 1}
+	if size > maxDim {
+		l.block = (size + maxDim - 1) / maxDim
+	} else if size > 0 {
+		l.cell = maxDim / size
+		if l.cell > maxCellPixels {
+			l.cell = maxCellPixels
+		}
+	}
+	l.dim = (size + l.block - 1) / l.block * l.cell
+	return l
+}
+
+// cells returns the number of output cells along each axis
+func (l layout) cells() int {
+	return (l.size + l.block - 1) / l.block
+}
+
+// cellColor computes the color of the output cell (cx, cy). When the cell
+// covers several tiles, the most common owner among them wins so thin
+// borders of territory stay visible, drawn over the center tile's base.
+func (l layout) cellColor(g *Grid, cx, cy int) color.RGBA {
+	x0, y0 := cx*l.block, cy*l.block
+	if l.block == 1 {
+		i := y0*g.Size + x0
+		if owner := g.Owners[i]; owner >= 0 {
+			return blend(g.Base[i], ownerColor(owner))
+		}
+		return g.Base[i]
+	}
+
+	x1, y1 := min(x0+l.block, g.Size), min(y0+l.block, g.Size)
+	cx0, cy0 := (x0+x1)/2, (y0+y1)/2
+	base := g.Base[cy0*g.Size+cx0]
+
+	var counts map[int]int
+	best, bestCount := -1, 0
+	for y := y0; y < y1; y++ {
+		row := g.Owners[y*g.Size : y*g.Size+g.Size]
+		for x := x0; x < x1; x++ {
+			owner := row[x]
+			if owner < 0 {
+				continue
+			}
+			if counts == nil {
+				counts = make(map[int]int)
+			}
+			counts[owner]++
+			if c := counts[owner]; c > bestCount || (c == bestCount && owner < best) {
+				best, bestCount = owner, c
+			}
+		}
+	}
+	if best < 0 {
+		return base
+	}
+	return blend(base, ownerColor(best))
+}
+
+// fillCell paints one output cell
+func (l layout) fillCell(img interface{ Set(x, y int, c color.Color) }, cx, cy int, c color.Color) {
+	for py := cy * l.cell; py < (cy+1)*l.cell; py++ {
+		for px := cx * l.cell; px < (cx+1)*l.cell; px++ {
+			img.Set(px, py, c)
+		}
+	}
+}
+
+// fillCellIndex paints one output cell of a paletted image
+func (l layout) fillCellIndex(img *image.Paletted, cx, cy int, c color.Color) {
+	idx := uint8(img.Palette.Index(c))
+	for py := cy * l.cell; py < (cy+1)*l.cell; py++ {
+		for px := cx * l.cell; px < (cx+1)*l.cell; px++ {
+			img.SetColorIndex(px, py, idx)
+		}
+	}
+}
+
+// draw renders the whole grid into an RGBA image
+func (l layout) draw(g *Grid) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, l.dim, l.dim))
+	n := l.cells()
+	for cy := 0; cy < n; cy++ {
+		for cx := 0; cx < n; cx++ {
+			l.fillCell(img, cx, cy, l.cellColor(g, cx, cy))
+		}
+	}
+	return img
+}
+
+// buildPalette returns a palette holding every color a grid can produce for
+// the given number of owners, or Plan9 when that exceeds 256 colors
+func buildPalette(g *Grid, owners int) color.Palette {
+	seen := make(map[color.RGBA]bool)
+	var bases []color.RGBA
+	for _, c := range g.Base {
+		if !seen[c] {
+			seen[c] = true
+			bases = append(bases, c)
+		}
+	}
+
+	p := make(color.Palette, 0, len(bases)*(owners+1))
+	for _, base := range bases {
+		p = append(p, base)
+		for owner := 0; owner < owners && owner < len(OwnerPalette); owner++ {
+			p = append(p, blend(base, ownerColor(owner)))
+		}
+	}
+	if len(p) > 256 {
+		return palette.Plan9
+	}
+	return p
+}
@@ -0,0 +1,197 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/game/worldgen"
+)
+
+// Timelapse defaults
+const (
+	DefaultMaxFrames = 120
+	DefaultDelay     = 10  // Frame delay in 100ths of a second
+	finalFrameDelay  = 300 // Hold the final state for 3 seconds
+)
+
+// Options controls rendered output
+type Options struct {
+	MaxDim    int // Longest side in pixels (0 = DefaultMaxDim)
+	MaxFrames int // Timelapse only: ticks are merged to stay under this (0 = DefaultMaxFrames)
+	Delay     int // Timelapse only: delay between frames in 100ths of a second (0 = DefaultDelay)
+}
+
+// Legend maps each agent to the owner color used in rendered images
+type Legend struct {
+	AgentID uuid.UUID `json:"agent_id"`
+	Name    string    `json:"name"`
+	Color   string    `json:"color"`
+}
+
+// WriteMapPNG renders the current world with territory overlaid as a PNG
+func WriteMapPNG(w io.Writer, engine *game.Engine, opts Options) error {
+	grid, owners := baseGrid(engine)
+	world := engine.GetWorld()
+	for y := 0; y < grid.Size; y++ {
+		for x := 0; x < grid.Size; x++ {
+			tile := world.GetTile(game.Position{X: x, Y: y})
+			if tile != nil && tile.OwnerID != nil {
+				if owner, ok := owners[*tile.OwnerID]; ok {
+					grid.Owners[y*grid.Size+x] = owner
+				}
+			}
+		}
+	}
+
+	l := newLayout(grid.Size, opts.MaxDim)
+	return png.Encode(w, l.draw(grid))
+}
+
+// WriteTimelapseGIF renders territory growth from the game's recorded tile
+// changes as an animated GIF
+func WriteTimelapseGIF(w io.Writer, engine *game.Engine, opts Options) error {
+	grid, owners := baseGrid(engine)
+	history := engine.History()
+
+	ticks := make([][]game.TileChange, len(history))
+	for i, record := range history {
+		ticks[i] = record.Tiles
+	}
+	return encodeTimelapse(w, grid, ticks, func(change game.TileChange) int {
+		if change.OwnerID == nil {
+			return -1
+		}
+		if owner, ok := owners[*change.OwnerID]; ok {
+			return owner
+		}
+		return -1
+	}, len(owners), opts)
+}
+
+// Legends returns the agent colors used by WriteMapPNG and WriteTimelapseGIF
+func Legends(engine *game.Engine) []Legend {
+	agents := engine.AgentSnapshots()
+	legends := make([]Legend, len(agents))
+	for i, agent := range agents {
+		c := ownerColor(i)
+		legends[i] = Legend{
+			AgentID: agent.ID,
+			Name:    agent.Name,
+			Color:   "#" + hexByte(c.R) + hexByte(c.G) + hexByte(c.B),
+		}
+	}
+	return legends
+}
+
+// baseGrid builds an unowned grid of biome colors and assigns owner indexes
+// to agents in AgentSnapshots order
+func baseGrid(engine *game.Engine) (*Grid, map[uuid.UUID]int) {
+	world := engine.GetWorld()
+	grid := NewGrid(world.Size())
+
+	biomes := worldgen.DefaultBiomeRegistry()
+	colors := make(map[string]color.RGBA)
+	for y := 0; y < grid.Size; y++ {
+		for x := 0; x < grid.Size; x++ {
+			tile := world.GetTile(game.Position{X: x, Y: y})
+			if tile == nil {
+				continue
+			}
+			c, ok := colors[tile.Biome]
+			if !ok {
+				c, _ = ParseHexColor(biomes.GetBiomeColor(worldgen.BiomeType(tile.Biome)))
+				colors[tile.Biome] = c
+			}
+			grid.Base[y*grid.Size+x] = c
+		}
+	}
+
+	owners := make(map[uuid.UUID]int)
+	for i, agent := range engine.AgentSnapshots() {
+		owners[agent.ID] = i
+	}
+	return grid, owners
+}
+
+// encodeTimelapse plays tile changes over the grid and encodes one frame per
+// group of ticks. Frames after the first only cover the changed area.
+func encodeTimelapse(w io.Writer, grid *Grid, ticks [][]game.TileChange, ownerOf func(game.TileChange) int, owners int, opts Options) error {
+	maxFrames := opts.MaxFrames
+	if maxFrames <= 0 {
+		maxFrames = DefaultMaxFrames
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	stride := (len(ticks) + maxFrames - 1) / maxFrames
+	if stride < 1 {
+		stride = 1
+	}
+
+	l := newLayout(grid.Size, opts.MaxDim)
+	pal := buildPalette(grid, owners)
+	canvas := image.NewPaletted(image.Rect(0, 0, l.dim, l.dim), pal)
+	n := l.cells()
+	for cy := 0; cy < n; cy++ {
+		for cx := 0; cx < n; cx++ {
+			l.fillCellIndex(canvas, cx, cy, l.cellColor(grid, cx, cy))
+		}
+	}
+
+	anim := &gif.GIF{
+		Image: []*image.Paletted{clonePaletted(canvas, canvas.Bounds())},
+		Delay: []int{delay},
+		Config: image.Config{
+			ColorModel: pal,
+			Width:      l.dim,
+			Height:     l.dim,
+		},
+	}
+
+	for start := 0; start < len(ticks); start += stride {
+		dirty := make(map[image.Point]bool)
+		for _, tick := range ticks[start:min(start+stride, len(ticks))] {
+			for _, change := range tick {
+				grid.SetOwner(change.X, change.Y, ownerOf(change))
+				dirty[image.Point{X: change.X / l.block, Y: change.Y / l.block}] = true
+			}
+		}
+		if len(dirty) == 0 {
+			anim.Delay[len(anim.Delay)-1] += delay // Nothing changed; extend the previous frame
+			continue
+		}
+
+		var changed image.Rectangle
+		for cell := range dirty {
+			l.fillCellIndex(canvas, cell.X, cell.Y, l.cellColor(grid, cell.X, cell.Y))
+			r := image.Rect(cell.X*l.cell, cell.Y*l.cell, (cell.X+1)*l.cell, (cell.Y+1)*l.cell)
+			changed = changed.Union(r)
+		}
+		anim.Image = append(anim.Image, clonePaletted(canvas, changed))
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	anim.Delay[len(anim.Delay)-1] = max(anim.Delay[len(anim.Delay)-1], finalFrameDelay)
+	return gif.EncodeAll(w, anim)
+}
+
+// clonePaletted copies a region of a paletted image into a new frame
+func clonePaletted(src *image.Paletted, r image.Rectangle) *image.Paletted {
+	dst := image.NewPaletted(r, src.Palette)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		copy(dst.Pix[dst.PixOffset(r.Min.X, y):dst.PixOffset(r.Max.X, y)], src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)])
+	}
+	return dst
+}
+
+// hexByte formats a byte as two lowercase hex digits
+func hexByte(b uint8) string {
+	const digits = "0123456789abcdef"
+	return string([]byte{digits[b>>4], digits[b&0x0F]})
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+func TestNewLayout(t *testing.T) {
+	cases := []struct {
+		size, maxDim     int
+		block, cell, dim int
+	}{
+		{size: 16, maxDim: 256, block: 1, cell: 16, dim: 256},
+		{size: 100, maxDim: 256, block: 1, cell: 2, dim: 200},
+		{size: 2048, maxDim: 512, block: 4, cell: 1, dim: 512},
+		{size: 1000, maxDim: 512, block: 2, cell: 1, dim: 500},
+	}
+
+	for _, c := range cases {
+		l := newLayout(c.size, c.maxDim)
+		if l.block != c.block || l.cell != c.cell || l.dim != c.dim {
+			t.Errorf("size %d max %d: got block=%d cell=%d dim=%d, want %d/%d/%d",
+				c.size, c.maxDim, l.block, l.cell, l.dim, c.block, c.cell, c.dim)
+		}
+	}
+}
+
+func TestCellColor_DownsampledOwnerWins(t *testing.T) {
+	g := NewGrid(4)
+	grey := color.RGBA{R: 100, G: 100, B: 100, A: 255}
+	for i := range g.Base {
+		g.Base[i] = grey
+	}
+	g.SetOwner(0, 0, 1) // One owned tile in the top-left 2x2 block
+
+	l := newLayout(4, 2)
+	if got, want := l.cellColor(g, 0, 0), blend(grey, ownerColor(1)); got != want {
+		t.Errorf("expected owned block color %v, got %v", want, got)
+	}
+	if got := l.cellColor(g, 1, 1); got != grey {
+		t.Errorf("expected unowned block to keep base color, got %v", got)
+	}
+}
+
+func newTestEngine(t *testing.T) (*game.Engine, *game.Agent) {
+	t.Helper()
+	m, err := game.ParseAuthoredMap([]byte("1...\n....\n..~~\n..~2"))
+	if err != nil {
+		t.Fatalf("failed to parse map: %v", err)
+	}
+	cfg := config.Default().Game
+	cfg.MapSize = m.Size()
+	engine := game.NewEngineWithAuthoredMap(uuid.New(), cfg, config.DefaultBalanceConfig(), m, nil, nil, nil, 1)
+
+	agent := game.NewAgent(engine.ID, "Alice", "", game.Position{}, 5)
+	if err := engine.AddAgent(agent); err != nil {
+		t.Fatalf("failed to add agent: %v", err)
+	}
+	return engine, agent
+}
+
+func TestWriteMapPNG(t *testing.T) {
+	engine, agent := newTestEngine(t)
+	engine.GetWorld().SetOwner(game.Position{X: 1, Y: 1}, &agent.ID)
+
+	var buf bytes.Buffer
+	if err := WriteMapPNG(&buf, engine, Options{MaxDim: 64}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+
+	if img.Bounds().Dx() != 64 {
+		t.Errorf("expected 64px wide image, got %d", img.Bounds().Dx())
+	}
+	owned := color.RGBAModel.Convert(img.At(16+8, 16+8)).(color.RGBA)
+	unowned := color.RGBAModel.Convert(img.At(8, 8)).(color.RGBA)
+	if owned == unowned {
+		t.Error("expected owned tile to be drawn differently from unowned tile")
+	}
+}
+
+func TestEncodeTimelapse(t *testing.T) {
+	g := NewGrid(8)
+	ticks := [][]game.TileChange{
+		{{X: 1, Y: 1}},
+		{}, // Quiet tick: merged into the previous frame's delay
+		{{X: 2, Y: 2}, {X: 3, Y: 2}},
+	}
+	ownerOf := func(game.TileChange) int { return 0 }
+
+	var buf bytes.Buffer
+	if err := encodeTimelapse(&buf, g, ticks, ownerOf, 1, Options{MaxDim: 64, Delay: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("invalid GIF: %v", err)
+	}
+
+	if len(anim.Image) != 3 {
+		t.Fatalf("expected 3 frames (initial + 2 changes), got %d", len(anim.Image))
+	}
+	if anim.Delay[1] != 10 {
+		t.Errorf("expected quiet tick to extend frame delay to 10, got %d", anim.Delay[1])
+	}
+	if anim.Delay[2] != finalFrameDelay {
+		t.Errorf("expected final frame to be held for %d, got %d", finalFrameDelay, anim.Delay[2])
+	}
+	if b := anim.Image[2].Bounds(); b.Dx() != 16 || b.Dy() != 8 {
+		t.Errorf("expected last frame to cover only the two changed tiles, got %v", b)
+	}
+}
@@ -0,0 +1,211 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game/worldgen"
+	"gopkg.in/yaml.v3"
+)
+
+// Limits for per-game settings supplied by clients
+const (
+	MinTickSeconds   = 3
+	MaxTickSeconds   = 300
+	MaxPlayersLimit  = 16
+	MaxVisionRadius  = 50
+	MinCustomMapSize = 32
+	MaxCustomMapSize = 2048 // Largest map clients may request, the size of the map presets
+)
+
+// GameSettings holds per-game overrides of the server-wide game config.
+// Zero values inherit the server default.
+type GameSettings struct {
+	TickSeconds  float64 `json:"tick_seconds,omitempty"`
+	MaxPlayers   int     `json:"max_players,omitempty"`
+	VisionRadius int     `json:"vision_radius,omitempty"`
+	MapSize      string  `json:"map_size,omitempty"`    // tiny, small, medium, large, huge, massive
+	CustomSize   int     `json:"custom_size,omitempty"` // Overrides MapSize when > 0
+	MapPreset    string  `json:"map_preset,omitempty"`  // Map preset ID, e.g. "frozen_wastes"
+
+	// MapConfig is a full custom map config; takes precedence over MapPreset
+	MapConfig *worldgen.MapConfig `json:"map_config,omitempty"`
+
+	// AuthoredMap replaces procedural terrain with a hand-made map
+	AuthoredMap *AuthoredMap `json:"authored_map,omitempty"`
+
+	Seed          int64   `json:"seed,omitempty"`
+	WinCondition  string  `json:"win_condition,omitempty"` // "ticks" or "territory"
+	WinAfterTicks int     `json:"win_after_ticks,omitempty"`
+	WinThreshold  int     `json:"win_threshold,omitempty"` // Tiles needed for a "territory" win
+	BudgetUSD     float64 `json:"budget_usd,omitempty"`    // Pause once the estimated LLM cost reaches this
+
+	// Access settings are applied to the lobby rather than the game config
+	Visibility Visibility `json:"visibility,omitempty"` // public (default), unlisted or private
+	Password   string     `json:"password,omitempty"`
+
+	// Balance holds partial balance overrides using the same snake_case keys
+	// as the balance section of config.yaml, e.g. {"agent": {"default_hp": 5}}
+	Balance json.RawMessage `json:"balance,omitempty"`
+}
+
+// Apply validates the settings and returns copies of the given configs with
+// the overrides applied.
+func (s GameSettings) Apply(cfg config.GameConfig, balance config.BalanceConfig) (config.GameConfig, config.BalanceConfig, error) {
+	if s.TickSeconds != 0 {
+		if s.TickSeconds < MinTickSeconds || s.TickSeconds > MaxTickSeconds {
+			return cfg, balance, fmt.Errorf("tick_seconds must be between %d and %d", MinTickSeconds, MaxTickSeconds)
+		}
+		cfg.TickDuration = time.Duration(s.TickSeconds * float64(time.Second))
+	}
+
+	if s.MaxPlayers != 0 {
+		if s.MaxPlayers < 1 || s.MaxPlayers > MaxPlayersLimit {
+			return cfg, balance, fmt.Errorf("max_players must be between 1 and %d", MaxPlayersLimit)
+		}
+		cfg.MaxPlayers = s.MaxPlayers
+	}
+
+	if s.VisionRadius != 0 {
+		if s.VisionRadius < 1 || s.VisionRadius > MaxVisionRadius {
+			return cfg, balance, fmt.Errorf("vision_radius must be between 1 and %d", MaxVisionRadius)
+		}
+		cfg.VisionRadius = s.VisionRadius
+	}
+
+	// Presets and custom configs bring their own size; explicit size
+	// settings below override it
+	if s.MapConfig != nil {
+		if err := s.MapConfig.Validate(); err != nil {
+			return cfg, balance, fmt.Errorf("invalid map_config: %w", err)
+		}
+		cfg.Map.Size = string(s.MapConfig.Size)
+		cfg.Map.CustomSize = s.MapConfig.CustomSize
+		cfg.MapSize = 0
+	} else if s.MapPreset != "" {
+		preset, ok := worldgen.NewMapConfigRegistry().Get(s.MapPreset)
+		if !ok {
+			return cfg, balance, fmt.Errorf("unknown map_preset: %s", s.MapPreset)
+		}
+		cfg.Map.Preset = s.MapPreset
+		cfg.Map.Size = string(preset.Size)
+		cfg.Map.CustomSize = 0
+		cfg.MapSize = 0
+	}
+
+	if s.MapSize != "" {
+		if !isValidMapSize(s.MapSize) {
+			return cfg, balance, fmt.Errorf("unknown map_size: %s", s.MapSize)
+		}
+		cfg.Map.Size = s.MapSize
+		cfg.Map.CustomSize = 0
+		cfg.MapSize = 0 // Clear direct map_size so Map.Size takes effect
+	}
+
+	if s.CustomSize != 0 {
+		if s.CustomSize < MinCustomMapSize || s.CustomSize > MaxCustomMapSize {
+			return cfg, balance, fmt.Errorf("custom_size must be between %d and %d", MinCustomMapSize, MaxCustomMapSize)
+		}
+		cfg.Map.CustomSize = s.CustomSize
+		cfg.MapSize = 0
+	}
+
+	if (s.MapConfig != nil || s.MapSize != "") && cfg.GetMapSize() > MaxCustomMapSize {
+		return cfg, balance, fmt.Errorf("maps larger than %d are only available as the server default", MaxCustomMapSize)
+	}
+
+	if s.AuthoredMap != nil {
+		if s.MapConfig != nil || s.MapPreset != "" || s.MapSize != "" || s.CustomSize != 0 {
+			return cfg, balance, fmt.Errorf("authored_map cannot be combined with other map settings")
+		}
+		cfg.MapSize = s.AuthoredMap.Size() // Direct size wins over Map.Size/CustomSize
+		if rate := s.AuthoredMap.ResourceSpawnRate; rate != nil {
+			if *rate < 0 {
+				return cfg, balance, fmt.Errorf("resource_spawn_rate must not be negative")
+			}
+			cfg.ResourceSpawnRate = *rate
+		}
+	}
+
+	if s.Seed != 0 {
+		cfg.Map.Seed = s.Seed
+	}
+
+	switch s.WinCondition {
+	case "":
+	case config.WinConditionTicks, config.WinConditionTerritory:
+		cfg.WinCondition = s.WinCondition
+	default:
+		return cfg, balance, fmt.Errorf("unknown win_condition: %s", s.WinCondition)
+	}
+
+	if s.WinAfterTicks < 0 || s.WinThreshold < 0 {
+		return cfg, balance, fmt.Errorf("win_after_ticks and win_threshold must not be negative")
+	}
+	if s.WinAfterTicks > 0 {
+		cfg.WinAfterTicks = s.WinAfterTicks
+	}
+	if s.WinThreshold > 0 {
+		cfg.WinThreshold = s.WinThreshold
+	}
+	if cfg.WinCondition == config.WinConditionTerritory && cfg.WinThreshold <= 0 {
+		return cfg, balance, fmt.Errorf("win_condition territory requires win_threshold")
+	}
+
+	if s.BudgetUSD < 0 {
+		return cfg, balance, fmt.Errorf("budget_usd must not be negative")
+	}
+	if s.BudgetUSD > 0 {
+		// The server budget is a cap; games may only lower it
+		if cfg.BudgetUSD > 0 && s.BudgetUSD > cfg.BudgetUSD {
+			return cfg, balance, fmt.Errorf("budget_usd must not exceed the server budget of %.2f", cfg.BudgetUSD)
+		}
+		cfg.BudgetUSD = s.BudgetUSD
+	}
+
+	switch s.Visibility {
+	case "", VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+	default:
+		return cfg, balance, fmt.Errorf("unknown visibility: %s", s.Visibility)
+	}
+
+	if len(s.Balance) > 0 {
+		// Copy the slice so overrides never alias the server-wide config
+		balance.Upgrades.UpgradeCosts = append([]int(nil), balance.Upgrades.UpgradeCosts...)
+		// JSON is valid YAML, so decoding with yaml.v3 reuses the config's
+		// snake_case tags and only overwrites the keys that are present.
+		// Compacting first strips tabs, which YAML does not allow.
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, s.Balance); err != nil {
+			return cfg, balance, fmt.Errorf("invalid balance overrides: %w", err)
+		}
+		if err := yaml.Unmarshal(compact.Bytes(), &balance); err != nil {
+			return cfg, balance, fmt.Errorf("invalid balance overrides: %w", err)
+		}
+	}
+
+	return cfg, balance, nil
+}
+
+// baseMapConfig returns the map config the world should be generated from:
+// the custom config if given, otherwise the preset named in cfg (nil = default)
+func (s GameSettings) baseMapConfig(cfg config.GameConfig) *worldgen.MapConfig {
+	if s.MapConfig != nil {
+		return s.MapConfig
+	}
+	preset, _ := worldgen.NewMapConfigRegistry().Get(cfg.Map.Preset)
+	return preset
+}
+
+// isValidMapSize checks a map size preset name
+func isValidMapSize(size string) bool {
+	switch size {
+	case "tiny", "small", "medium", "large", "huge", "massive":
+		return true
+	default:
+		return false
+	}
+}
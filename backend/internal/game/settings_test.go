@@ -0,0 +1,103 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lucas/promptlands/internal/config"
+)
+
+func TestGameSettingsApply_Overrides(t *testing.T) {
+	base := config.Default().Game
+	settings := GameSettings{
+		TickSeconds:  5,
+		MaxPlayers:   2,
+		VisionRadius: 7,
+		MapSize:      "tiny",
+		WinCondition: config.WinConditionTerritory,
+		WinThreshold: 40,
+	}
+
+	cfg, _, err := settings.Apply(base, config.DefaultBalanceConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.TickDuration != 5*time.Second {
+		t.Errorf("expected tick duration 5s, got %v", cfg.TickDuration)
+	}
+	if cfg.MaxPlayers != 2 || cfg.VisionRadius != 7 {
+		t.Errorf("expected max players 2 and vision 7, got %d and %d", cfg.MaxPlayers, cfg.VisionRadius)
+	}
+	if cfg.GetMapSize() != 128 {
+		t.Errorf("expected tiny map, got size %d", cfg.GetMapSize())
+	}
+	if cfg.WinCondition != config.WinConditionTerritory || cfg.WinThreshold != 40 {
+		t.Errorf("expected territory win at 40, got %s at %d", cfg.WinCondition, cfg.WinThreshold)
+	}
+}
+
+func TestGameSettingsApply_PartialBalance(t *testing.T) {
+	balance := config.DefaultBalanceConfig()
+	settings := GameSettings{
+		Balance: json.RawMessage(`{"agent": {"default_hp": 9}}`),
+	}
+
+	_, got, err := settings.Apply(config.Default().Game, balance)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Agent.DefaultHP != 9 {
+		t.Errorf("expected default_hp 9, got %d", got.Agent.DefaultHP)
+	}
+	if got.Agent.DefaultMaxHP != balance.Agent.DefaultMaxHP {
+		t.Errorf("expected default_max_hp untouched (%d), got %d", balance.Agent.DefaultMaxHP, got.Agent.DefaultMaxHP)
+	}
+	if got.Combat != balance.Combat {
+		t.Errorf("expected combat balance untouched")
+	}
+}
+
+func TestGameSettingsApply_RejectsInvalid(t *testing.T) {
+	cases := map[string]GameSettings{
+		"tick too fast":          {TickSeconds: 0.5},
+		"too many players":       {MaxPlayers: MaxPlayersLimit + 1},
+		"unknown map size":       {MapSize: "gigantic"},
+		"unknown preset":         {MapPreset: "moon_base"},
+		"unknown win condition":  {WinCondition: "vibes"},
+		"territory no threshold": {WinCondition: config.WinConditionTerritory},
+		"bad balance":            {Balance: json.RawMessage(`{"agent": `)},
+		"custom size too large":  {CustomSize: MaxCustomMapSize + 1},
+		"massive map size":       {MapSize: "massive"},
+	}
+
+	for name, settings := range cases {
+		if _, _, err := settings.Apply(config.Default().Game, config.DefaultBalanceConfig()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGameSettingsApply_BudgetCappedByServer(t *testing.T) {
+	base := config.Default().Game
+	base.BudgetUSD = 5
+
+	if _, _, err := (GameSettings{BudgetUSD: 1e9}).Apply(base, config.DefaultBalanceConfig()); err == nil {
+		t.Error("expected error for budget above the server cap")
+	}
+
+	cfg, _, err := GameSettings{BudgetUSD: 2}.Apply(base, config.DefaultBalanceConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.BudgetUSD != 2 {
+		t.Errorf("expected lower budget 2, got %v", cfg.BudgetUSD)
+	}
+
+	cfg, _, _ = GameSettings{}.Apply(base, config.DefaultBalanceConfig())
+	if cfg.BudgetUSD != 5 {
+		t.Errorf("expected server budget 5 by default, got %v", cfg.BudgetUSD)
+	}
+}
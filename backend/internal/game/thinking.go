@@ -0,0 +1,84 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// thinkingInterval is the minimum time between agent_thinking messages for
+// one agent; faster deltas are batched
+const thinkingInterval = 150 * time.Millisecond
+
+type thinkingStreamKey struct{}
+
+// WithThinkingStream attaches a callback that streaming LLM clients call
+// with each new piece of the agent's reasoning as the response arrives
+func WithThinkingStream(ctx context.Context, stream func(delta string)) context.Context {
+	return context.WithValue(ctx, thinkingStreamKey{}, stream)
+}
+
+// ThinkingStreamFromContext returns the reasoning callback attached to ctx, if any
+func ThinkingStreamFromContext(ctx context.Context) (func(delta string), bool) {
+	stream, ok := ctx.Value(thinkingStreamKey{}).(func(delta string))
+	return stream, ok
+}
+
+// thinkingBroadcaster forwards an agent's streamed reasoning to spectators and
+// the agent's own player as agent_thinking messages while the tick is being
+// decided
+type thinkingBroadcaster struct {
+	viewers AgentViewers
+	gameID  uuid.UUID
+	tick    int
+	agentID uuid.UUID
+	pending strings.Builder
+	last    time.Time
+}
+
+// AgentViewers is implemented by broadcasters that can reach a game's
+// spectators and one agent's player without the other players. Reasoning is
+// only streamed through it so opponents never see it.
+type AgentViewers interface {
+	GetAgentViewerCount(gameID, agentID uuid.UUID) int
+	BroadcastToAgentViewers(gameID, agentID uuid.UUID, message interface{})
+}
+
+// newThinkingBroadcaster returns nil when streaming is disabled or nobody
+// can receive it
+func (e *Engine) newThinkingBroadcaster(tick int, agentID uuid.UUID) *thinkingBroadcaster {
+	if !e.config.StreamThinking {
+		return nil
+	}
+	viewers, ok := e.broadcaster.(AgentViewers)
+	if !ok || viewers.GetAgentViewerCount(e.ID, agentID) == 0 {
+		return nil
+	}
+	return &thinkingBroadcaster{viewers: viewers, gameID: e.ID, tick: tick, agentID: agentID}
+}
+
+// add queues a delta and sends the batch once thinkingInterval has passed
+func (t *thinkingBroadcaster) add(delta string) {
+	t.pending.WriteString(delta)
+	if time.Since(t.last) >= thinkingInterval {
+		t.flush()
+	}
+}
+
+// flush sends any queued reasoning
+func (t *thinkingBroadcaster) flush() {
+	if t.pending.Len() == 0 {
+		return
+	}
+	t.viewers.BroadcastToAgentViewers(t.gameID, t.agentID, map[string]interface{}{
+		"type":     "agent_thinking",
+		"game_id":  t.gameID,
+		"tick":     t.tick,
+		"agent_id": t.agentID,
+		"delta":    t.pending.String(),
+	})
+	t.pending.Reset()
+	t.last = time.Now()
+}
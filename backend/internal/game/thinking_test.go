@@ -0,0 +1,46 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// agentViewersBroadcaster counts viewers per agent and records private messages
+type agentViewersBroadcaster struct {
+	viewerBroadcaster
+	agentViewers map[uuid.UUID]int
+	sent         []uuid.UUID
+}
+
+func (b *agentViewersBroadcaster) GetAgentViewerCount(gameID, agentID uuid.UUID) int {
+	return b.agentViewers[agentID]
+}
+
+func (b *agentViewersBroadcaster) BroadcastToAgentViewers(gameID, agentID uuid.UUID, message interface{}) {
+	b.sent = append(b.sent, agentID)
+}
+
+func TestNewThinkingBroadcaster_NeedsViewers(t *testing.T) {
+	engine, alice, bob := newStatsTestEngine(t)
+	engine.config.StreamThinking = true
+	engine.broadcaster = &viewerBroadcaster{viewers: map[uuid.UUID]int{engine.ID: 2}}
+	if engine.newThinkingBroadcaster(1, alice.ID) != nil {
+		t.Error("expected no thinking stream through a broadcaster that reaches every player")
+	}
+
+	hub := &agentViewersBroadcaster{agentViewers: map[uuid.UUID]int{alice.ID: 1}}
+	engine.broadcaster = hub
+	if engine.newThinkingBroadcaster(1, bob.ID) != nil {
+		t.Error("expected no thinking stream without anyone allowed to see it")
+	}
+	thinking := engine.newThinkingBroadcaster(1, alice.ID)
+	if thinking == nil {
+		t.Fatal("expected a thinking stream for a watched agent")
+	}
+	thinking.add("hmm")
+	thinking.flush()
+	if len(hub.sent) != 1 || hub.sent[0] != alice.ID {
+		t.Errorf("expected alice's reasoning to go to her viewers only, got %v", hub.sent)
+	}
+}
@@ -3,11 +3,11 @@ package game
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/game/worldgen"
+	"github.com/lucas/promptlands/internal/logging"
 )
 
 // processTick handles a single game tick
@@ -21,19 +21,27 @@ func (e *Engine) processTick(ctx context.Context) {
 	}
 	e.mu.Unlock()
 
-	log.Printf("Game %s: Processing tick %d", e.ID, tick)
+	logger := e.logger.With(logging.KeyTick, tick)
+	ctx = logging.NewContext(ctx, logger)
+	logger.Debug("Processing tick")
+	timer := newPhaseTimer(tick)
+	defer func() { e.recordProfile(timer.finish()) }()
 
 	// Phase 1: Process respawns
 	respawnedAgents := e.processRespawns(tick)
+	timer.done("respawns", len(respawnedAgents))
 
 	// Phase 2: Passive energy income (before actions)
 	e.processPassiveIncome()
+	timer.done("passive_income", len(agents))
 
 	// Phase 2b: Spawn new resources based on biomes
-	spawnedObjects := e.processResourceSpawning()
+	spawnedObjects := e.processResourceSpawning(ctx)
+	timer.done("resource_spawning", len(spawnedObjects))
 
 	// Phase 2c: Auto-absorb resources from owned territory
 	absorptionResults := e.processResourceAbsorption()
+	timer.done("absorption", len(absorptionResults))
 
 	// Build context for each agent (skip dead agents)
 	aliveAgents := make([]*Agent, 0, len(agents))
@@ -43,51 +51,75 @@ func (e *Engine) processTick(ctx context.Context) {
 		}
 	}
 	contexts := e.buildAgentContexts(aliveAgents)
+	timer.done("contexts", len(contexts))
 
 	// Fan-out LLM requests with timeout
 	llmCtx, cancel := context.WithTimeout(ctx, e.config.TickDuration-2*time.Second)
 	actions := e.requestActions(llmCtx, contexts)
 	cancel()
+	timer.done("llm", len(actions))
 
 	// Add actions to resolver
 	e.resolver.AddActions(actions)
 
 	// Resolve conflicts (first-come priority)
 	orderedActions := e.resolver.Resolve()
+	timer.done("resolve", len(orderedActions))
 
 	// Process actions with full processor
 	processor := NewActionProcessor(e.world, e.agents, e.worldObjects, e.itemRegistry, e.recipeRegistry, tick, &e.balance, e.handlerRegistry)
 	results := processor.ProcessAll(orderedActions)
+	e.rememberActions(orderedActions, results)
+	e.rememberResults(results)
 
 	// Append territory resource absorption results
 	results = append(results, absorptionResults...)
+	timer.done("process", len(results))
 
 	// Phase 3: Trigger traps after movement
 	trapResults := e.processTrapTriggers(results)
 	results = append(results, trapResults...)
+	timer.done("traps", len(trapResults))
 
 	// Phase 3b: Auto-activate interactive objects (shrines, caches, portals)
 	interactiveResults := e.processInteractiveActivation(results)
 	results = append(results, interactiveResults...)
+	timer.done("interactives", len(interactiveResults))
 
 	// Phase 4: Process despawns
 	removedObjects := e.worldObjects.ProcessDespawns(tick)
 	depletedResources := e.worldObjects.ProcessDepletedResources()
 	removedObjects = append(removedObjects, depletedResources...)
+	timer.done("despawns", len(removedObjects))
 
 	// Collect messages from this tick
 	tickMessages := e.collectMessages(orderedActions)
+	e.recordStats(orderedActions, results)
 
 	// Build tick update
 	update := e.buildTickUpdate(tick, orderedActions, results, tickMessages, removedObjects, respawnedAgents, spawnedObjects)
+	e.recordTick(tick, update.Changes)
+	timer.done("update", len(update.Changes.Tiles))
 
 	// Broadcast to all connected clients with per-player visibility and inventory
 	if e.broadcaster != nil {
+		e.mu.RLock()
+		if e.profileUpdates {
+			update.Profile = timer.snapshot() // Phases up to and including building the update
+		}
+		e.mu.RUnlock()
+
 		e.broadcaster.BroadcastToGameWithVisibility(e.ID, update, e.getVisibleTilesForPlayer, e.getPlayerInventory)
 	}
+	timer.done("broadcast", 1)
+
+	// Commentary runs in the background with its own timeout
+	e.narrate(ctx, tick, update.Changes)
+
+	e.checkBudget()
 
 	// Check win condition
-	if tick >= e.config.WinAfterTicks {
+	if e.checkWinCondition(tick) {
 		e.endGame()
 	}
 }
@@ -102,7 +134,7 @@ func (e *Engine) processRespawns(tick int) []uuid.UUID {
 			pos := e.findEdgeSpawnPosition()
 			agent.Respawn(pos)
 			respawned = append(respawned, agent.ID)
-			log.Printf("Agent %s respawned at (%d,%d)", agent.Name, pos.X, pos.Y)
+			e.logger.Info("Agent respawned", logging.KeyTick, tick, logging.KeyAgentID, agent.ID, "agent", agent.Name, "x", pos.X, "y", pos.Y)
 		}
 	}
 
@@ -248,7 +280,7 @@ func (e *Engine) processPassiveIncome() {
 }
 
 // processResourceSpawning spawns resource nodes each tick based on biome types
-func (e *Engine) processResourceSpawning() []WorldObjectSnapshot {
+func (e *Engine) processResourceSpawning(ctx context.Context) []WorldObjectSnapshot {
 	if e.biomeRegistry == nil || e.lootTables == nil || e.spawnRng == nil {
 		return nil
 	}
@@ -342,7 +374,7 @@ func (e *Engine) processResourceSpawning() []WorldObjectSnapshot {
 	}
 
 	if len(spawned) > 0 {
-		log.Printf("Game %s: Spawned %d resource nodes (total: %d/%d)", e.ID, len(spawned), currentResources+len(spawned), maxResources)
+		logging.FromContext(ctx).Debug("Spawned resource nodes", "count", len(spawned), "total", currentResources+len(spawned), "max", maxResources)
 	}
 
 	return spawned
@@ -406,6 +438,8 @@ func (e *Engine) processTrapTriggers(results []ActionResult) []ActionResult {
 					for _, pos := range ownedTiles {
 						e.world.SetOwner(pos, nil)
 					}
+					trapResult.ClearedTiles = ownedTiles
+					trapResult.Killed = &agent.ID
 					if agent.Inventory != nil {
 						agent.Inventory.Clear()
 					}
@@ -575,6 +609,12 @@ func (e *Engine) buildTickUpdate(tick int, actions []Action, results []ActionRes
 				})
 			}
 		}
+		for _, tilePos := range result.ClearedTiles {
+			tileChanges = append(tileChanges, TileChange{
+				X: tilePos.X,
+				Y: tilePos.Y,
+			})
+		}
 	}
 
 	// Collect agent snapshots
@@ -0,0 +1,235 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+)
+
+// LLMStats counts a game's LLM calls
+type LLMStats struct {
+	Requests     int64 `json:"requests"`
+	Errors       int64 `json:"errors"`
+	Timeouts     int64 `json:"timeouts"`
+	AvgLatencyMs int64 `json:"avg_latency_ms"`
+}
+
+// llmCounters accumulates LLM call statistics without locking
+type llmCounters struct {
+	requests  atomic.Int64
+	errors    atomic.Int64
+	timeouts  atomic.Int64
+	latencyNs atomic.Int64
+}
+
+// record counts one LLM call
+func (c *llmCounters) record(latency time.Duration, err error) {
+	c.requests.Add(1)
+	c.latencyNs.Add(int64(latency))
+	if err != nil {
+		c.errors.Add(1)
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.timeouts.Add(1)
+		}
+	}
+}
+
+// snapshot returns the current statistics
+func (c *llmCounters) snapshot() LLMStats {
+	stats := LLMStats{
+		Requests: c.requests.Load(),
+		Errors:   c.errors.Load(),
+		Timeouts: c.timeouts.Load(),
+	}
+	if stats.Requests > 0 {
+		stats.AvgLatencyMs = c.latencyNs.Load() / stats.Requests / int64(time.Millisecond)
+	}
+	return stats
+}
+
+// LLMStats returns the game's LLM call statistics
+func (e *Engine) LLMStats() LLMStats {
+	return e.llmStats.snapshot()
+}
+
+// TokenUsage is the token count and estimated cost of one LLM response
+type TokenUsage struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+}
+
+type usageReporterKey struct{}
+
+// WithUsageReporter attaches a callback that LLM clients call with the token
+// usage of each response, retries included
+func WithUsageReporter(ctx context.Context, report func(TokenUsage)) context.Context {
+	return context.WithValue(ctx, usageReporterKey{}, report)
+}
+
+// ReportUsage passes a response's token usage to the reporter attached to ctx, if any
+func ReportUsage(ctx context.Context, usage TokenUsage) {
+	if report, ok := ctx.Value(usageReporterKey{}).(func(TokenUsage)); ok {
+		report(usage)
+	}
+}
+
+// Usage totals LLM requests, tokens and estimated cost
+type Usage struct {
+	Requests         int     `json:"requests"`
+	Errors           int     `json:"errors"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	LatencyMs        int64   `json:"latency_ms"` // Summed over requests
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// Add returns the sum of two usage totals
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		Requests:         u.Requests + o.Requests,
+		Errors:           u.Errors + o.Errors,
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+		LatencyMs:        u.LatencyMs + o.LatencyMs,
+		CostUSD:          u.CostUSD + o.CostUSD,
+	}
+}
+
+// AgentUsage is one agent's LLM usage
+type AgentUsage struct {
+	AgentID  uuid.UUID  `json:"agent_id"`
+	Name     string     `json:"name"`
+	PlayerID *uuid.UUID `json:"player_id,omitempty"`
+	Usage
+}
+
+// PlayerUsage is the LLM usage of all agents controlled by one player
+type PlayerUsage struct {
+	PlayerID uuid.UUID `json:"player_id"`
+	Usage
+}
+
+// GameUsage is a game's LLM usage in total, per agent and per player
+type GameUsage struct {
+	Usage
+	BudgetUSD      float64       `json:"budget_usd,omitempty"`
+	BudgetExceeded bool          `json:"budget_exceeded"`
+	Agents         []AgentUsage  `json:"agents"`
+	Players        []PlayerUsage `json:"players"`
+}
+
+// Usage returns the game's LLM usage and estimated cost so far
+func (e *Engine) Usage() GameUsage {
+	e.mu.RLock()
+	agents := make(map[uuid.UUID]AgentUsage, len(e.agents))
+	for id, agent := range e.agents {
+		agents[id] = AgentUsage{AgentID: id, Name: agent.Name, PlayerID: agent.PlayerID}
+	}
+	usage := GameUsage{BudgetUSD: e.config.BudgetUSD, BudgetExceeded: e.budgetExceeded}
+	e.mu.RUnlock()
+
+	byPlayer := make(map[uuid.UUID]Usage)
+	usage.Agents = []AgentUsage{}
+	for _, stats := range e.Stats() {
+		agent, ok := agents[stats.AgentID]
+		if !ok {
+			agent = AgentUsage{AgentID: stats.AgentID, Name: stats.Name} // Removed from the game
+		}
+		agent.Usage = stats.usage()
+		usage.Agents = append(usage.Agents, agent)
+		usage.Usage = usage.Usage.Add(agent.Usage)
+		if agent.PlayerID != nil {
+			byPlayer[*agent.PlayerID] = byPlayer[*agent.PlayerID].Add(agent.Usage)
+		}
+	}
+
+	sort.Slice(usage.Agents, func(i, j int) bool {
+		if usage.Agents[i].Name != usage.Agents[j].Name {
+			return usage.Agents[i].Name < usage.Agents[j].Name
+		}
+		return usage.Agents[i].AgentID.String() < usage.Agents[j].AgentID.String()
+	})
+
+	usage.Players = make([]PlayerUsage, 0, len(byPlayer))
+	for id, total := range byPlayer {
+		usage.Players = append(usage.Players, PlayerUsage{PlayerID: id, Usage: total})
+	}
+	sort.Slice(usage.Players, func(i, j int) bool {
+		return usage.Players[i].PlayerID.String() < usage.Players[j].PlayerID.String()
+	})
+	return usage
+}
+
+// checkBudget pauses the game the first time its estimated LLM cost reaches
+// the budget. Resuming it continues without pausing again.
+func (e *Engine) checkBudget() {
+	budget := e.config.BudgetUSD
+	if budget <= 0 {
+		return
+	}
+	cost := e.stats.costUSD()
+
+	e.mu.Lock()
+	if e.budgetExceeded || cost < budget {
+		e.mu.Unlock()
+		return
+	}
+	e.budgetExceeded = true
+	e.mu.Unlock()
+
+	e.logger.Warn("LLM budget exceeded, pausing game", "cost_usd", cost, "budget_usd", budget)
+	e.Pause()
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastToGame(e.ID, map[string]interface{}{
+			"type":       "budget_exceeded",
+			"game_id":    e.ID,
+			"cost_usd":   cost,
+			"budget_usd": budget,
+		})
+	}
+}
+
+// Approximate heap cost of game state entries, used by MemoryEstimate
+const (
+	tileBytes         = int64(unsafe.Sizeof(Tile{})) + 8 // Tile plus its row pointer
+	ownedTileBytes    = 16 + int64(unsafe.Sizeof(Position{}))
+	worldObjectBytes  = 256
+	agentBytes        = 2048
+	tileChangeBytes   = int64(unsafe.Sizeof(TileChange{})) + 16
+	resultBytes       = int64(unsafe.Sizeof(ActionResult{})) + 64
+	messageBytes      = int64(unsafe.Sizeof(GameMessage{}))
+	promptRecordBytes = int64(unsafe.Sizeof(PromptRecord{}))
+)
+
+// MemoryEstimate returns a rough size in bytes of the game's in-memory state:
+// the tile grid, world objects, agents and recorded history
+func (e *Engine) MemoryEstimate() int64 {
+	size := int64(e.world.Size())
+	total := size * size * tileBytes
+	for _, owned := range e.world.GetOwnershipMap() {
+		total += int64(owned) * ownedTileBytes
+	}
+	total += int64(e.worldObjects.Count()) * worldObjectBytes
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	total += int64(len(e.agents)) * agentBytes
+	total += int64(len(e.messages)) * messageBytes
+	for _, record := range e.history {
+		total += int64(len(record.Tiles)) * tileChangeBytes
+		total += int64(len(record.Results)) * resultBytes
+		total += int64(len(record.Messages)) * messageBytes
+	}
+	for _, record := range e.prompts {
+		total += int64(len(record.Prompt)+len(record.Response)) + promptRecordBytes
+	}
+	return total
+}
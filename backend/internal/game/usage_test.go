@@ -0,0 +1,126 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+)
+
+func TestEngineUsage(t *testing.T) {
+	engine, alice, bob := newStatsTestEngine(t)
+	player := uuid.New()
+	alice.PlayerID = &player
+
+	engine.stats.recordLLMRequest(alice.ID, 200*time.Millisecond, []TokenUsage{
+		{PromptTokens: 1000, CompletionTokens: 50, CostUSD: 0.002},
+		{PromptTokens: 1000, CompletionTokens: 40, CostUSD: 0.002}, // Retried request
+	}, nil)
+	engine.stats.recordLLMRequest(bob.ID, 100*time.Millisecond, nil, errors.New("bad response"))
+
+	usage := engine.Usage()
+	if usage.Requests != 2 || usage.Errors != 1 || usage.PromptTokens != 2000 || usage.CompletionTokens != 90 {
+		t.Errorf("unexpected game totals: %+v", usage.Usage)
+	}
+	if usage.CostUSD < 0.0039 || usage.CostUSD > 0.0041 {
+		t.Errorf("expected cost of 0.004, got %v", usage.CostUSD)
+	}
+	if len(usage.Agents) != 2 || usage.Agents[0].Name != "Alice" || usage.Agents[0].LatencyMs != 200 {
+		t.Errorf("unexpected agent usage: %+v", usage.Agents)
+	}
+	if len(usage.Players) != 1 || usage.Players[0].PlayerID != player || usage.Players[0].PromptTokens != 2000 {
+		t.Errorf("expected usage for alice's player only, got %+v", usage.Players)
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	engine, alice, _ := newStatsTestEngine(t)
+	engine.config.BudgetUSD = 0.01
+
+	engine.stats.recordLLMRequest(alice.ID, 0, []TokenUsage{{CostUSD: 0.005}}, nil)
+	engine.checkBudget()
+	if engine.IsPaused() {
+		t.Fatal("expected game under budget to keep running")
+	}
+
+	engine.stats.recordLLMRequest(alice.ID, 0, []TokenUsage{{CostUSD: 0.006}}, nil)
+	engine.checkBudget()
+	if !engine.IsPaused() || !engine.Usage().BudgetExceeded {
+		t.Fatal("expected game over budget to pause")
+	}
+
+	engine.SetPaused(false)
+	engine.checkBudget()
+	if engine.IsPaused() {
+		t.Error("expected a resumed game not to be paused again")
+	}
+}
+
+func TestManagerPlayerUsage(t *testing.T) {
+	m := NewManager(config.Default().Game, nil, nil, nil, nil, nil)
+	first, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	second, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 2}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+
+	alice, err := m.JoinGame(first.ID, "Alice", "", "", "")
+	if err != nil {
+		t.Fatalf("failed to join: %v", err)
+	}
+	player := *alice.PlayerID
+	token := first.GetLobby().PlayerToken(alice.ID)
+	again, err := m.JoinGameWithModel(second.ID, player, "Alice", "", "", "", ModelSettings{})
+	if err != nil {
+		t.Fatalf("failed to join again: %v", err)
+	}
+	first.stats.recordLLMRequest(alice.ID, 0, []TokenUsage{{PromptTokens: 100}}, nil)
+	second.stats.recordLLMRequest(again.ID, 0, []TokenUsage{{PromptTokens: 50}}, nil)
+
+	if _, err := m.PlayerUsage(player, "guess"); err != ErrNotPlayer {
+		t.Errorf("expected ErrNotPlayer without the player's token, got %v", err)
+	}
+	if usage, err := m.PlayerUsage(player, token); err != nil || usage.PromptTokens != 150 {
+		t.Errorf("expected usage summed over both games, got %+v, %v", usage, err)
+	}
+
+	m.RemoveGame(first.ID)
+	if usage, err := m.PlayerUsage(player, token); err != nil || usage.PromptTokens != 150 {
+		t.Errorf("expected usage of removed games to be kept, got %+v, %v", usage, err)
+	}
+}
+
+func TestManagerPrunePlayers(t *testing.T) {
+	m := NewManager(config.Default().Game, nil, nil, nil, nil, nil)
+	engine, err := m.CreateGameWithSettings(GameSettings{CustomSize: 32, Seed: 1}, "host")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	alice, err := m.JoinGame(engine.ID, "Alice", "", "", "")
+	if err != nil {
+		t.Fatalf("failed to join: %v", err)
+	}
+	player := *alice.PlayerID
+	token := engine.GetLobby().PlayerToken(alice.ID)
+	later := time.Now().Add(2 * playerRetention)
+
+	m.prunePlayers(later)
+	if !m.IsPlayer(player, token) {
+		t.Fatal("expected a player in a live game to be kept")
+	}
+
+	m.RemoveGame(engine.ID)
+	m.prunePlayers(time.Now())
+	if !m.IsPlayer(player, token) {
+		t.Fatal("expected a player to be kept for a while after its last game")
+	}
+	m.prunePlayers(later)
+	if m.IsPlayer(player, token) || len(m.players) != 0 {
+		t.Errorf("expected the player to be forgotten, got %d players", len(m.players))
+	}
+}
@@ -560,6 +560,13 @@ func (m *WorldObjectManager) SnapshotVisible(center Position, radius int, viewer
 	return snapshots
 }
 
+// Count returns the number of world objects
+func (m *WorldObjectManager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.objects)
+}
+
 // CountResourceNodes returns the number of resource node objects
 func (m *WorldObjectManager) CountResourceNodes() int {
 	m.mu.RLock()
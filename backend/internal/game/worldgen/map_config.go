@@ -2,7 +2,9 @@ package worldgen
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"sort"
 )
 
 // MapSize represents predefined map sizes for open-world feel
@@ -391,7 +393,8 @@ func NewMapConfigRegistry() *MapConfigRegistry {
 		Configs: make(map[string]*MapConfig),
 	}
 
-	// Register built-in presets
+	// Register the default world and built-in presets
+	registry.Register(DefaultMapConfig())
 	for _, preset := range GetMapPresets() {
 		registry.Register(preset)
 	}
@@ -410,11 +413,95 @@ func (r *MapConfigRegistry) Get(id string) (*MapConfig, bool) {
 	return config, ok
 }
 
-// List returns all available map configuration IDs
+// List returns all available map configuration IDs in sorted order
 func (r *MapConfigRegistry) List() []string {
 	ids := make([]string, 0, len(r.Configs))
 	for id := range r.Configs {
 		ids = append(ids, id)
 	}
+	sort.Strings(ids)
 	return ids
 }
+
+// Limits for user-supplied map configs
+const (
+	MinMapSize            = 32
+	MaxMapSize            = 4096
+	MaxNoiseOctaves       = 8
+	MaxRiverCount         = 64
+	MaxStructuresPerMap   = 256
+	MaxStructuresPerChunk = 4.0
+)
+
+// Validate checks a map config for values the generator cannot handle.
+// It is meant for configs uploaded by clients; built-in presets always pass.
+func (c *MapConfig) Validate() error {
+	switch c.Size {
+	case "", MapSizeTiny, MapSizeSmall, MapSizeMedium, MapSizeLarge, MapSizeHuge, MapSizeMassive:
+	default:
+		return fmt.Errorf("unknown size: %s", c.Size)
+	}
+	if c.CustomSize != 0 && (c.CustomSize < MinMapSize || c.CustomSize > MaxMapSize) {
+		return fmt.Errorf("custom_size must be between %d and %d", MinMapSize, MaxMapSize)
+	}
+	if c.ChunkSize < 0 || c.ChunkSize > c.GetActualSize() {
+		return fmt.Errorf("chunk_size must be between 0 and the map size")
+	}
+
+	layers := map[string]NoiseLayerConfig{
+		"elevation_noise":   c.ElevationNoise,
+		"moisture_noise":    c.MoistureNoise,
+		"temperature_noise": c.TemperatureNoise,
+		"variation_noise":   c.VariationNoise,
+	}
+	for name, layer := range layers {
+		if layer.Octaves < 0 || layer.Octaves > MaxNoiseOctaves {
+			return fmt.Errorf("%s.octaves must be between 0 and %d", name, MaxNoiseOctaves)
+		}
+		if layer.Frequency < 0 || layer.Persistence < 0 || layer.Amplitude < 0 {
+			return fmt.Errorf("%s values must not be negative", name)
+		}
+	}
+
+	if len(c.BiomeDistributions) == 0 {
+		return fmt.Errorf("biome_distributions must not be empty")
+	}
+	biomes := DefaultBiomeRegistry()
+	for i, d := range c.BiomeDistributions {
+		if _, ok := biomes.GetBiome(d.Biome); !ok {
+			return fmt.Errorf("biome_distributions[%d]: unknown biome %q", i, d.Biome)
+		}
+		if !validRange(d.ElevationMin, d.ElevationMax) || !validRange(d.MoistureMin, d.MoistureMax) || !validRange(d.TemperatureMin, d.TemperatureMax) {
+			return fmt.Errorf("biome_distributions[%d]: ranges must satisfy 0 <= min <= max <= 1", i)
+		}
+	}
+
+	if c.OceanBorderWidth < 0 || c.OceanBorderWidth*2 >= c.GetActualSize() {
+		return fmt.Errorf("ocean_border_width must leave land in the middle of the map")
+	}
+	if c.RiverCount < 0 || c.RiverCount > MaxRiverCount {
+		return fmt.Errorf("river_count must be between 0 and %d", MaxRiverCount)
+	}
+	if c.LakeChance < 0 || c.LakeChance > 1 || c.ResourceDensity < 0 || c.DifficultyMultiplier < 0 {
+		return fmt.Errorf("lake_chance must be within [0, 1]; resource_density and difficulty_multiplier must not be negative")
+	}
+
+	s := c.Structures
+	for _, v := range []float64{s.ShrinesPerChunk, s.CachesPerChunk, s.ObelisksPerChunk, s.RuinsPerChunk} {
+		if v < 0 || v > MaxStructuresPerChunk {
+			return fmt.Errorf("per-chunk structure rates must be between 0 and %v", MaxStructuresPerChunk)
+		}
+	}
+	for _, v := range []int{s.PortalPairsPerMap, s.DungeonsPerMap, s.VillagesPerMap} {
+		if v < 0 || v > MaxStructuresPerMap {
+			return fmt.Errorf("per-map structure counts must be between 0 and %d", MaxStructuresPerMap)
+		}
+	}
+
+	return nil
+}
+
+// validRange checks that min <= max and both lie within [0, 1]
+func validRange(lo, hi float64) bool {
+	return lo >= 0 && hi <= 1 && lo <= hi
+}
@@ -0,0 +1,37 @@
+package worldgen
+
+import (
+	"testing"
+)
+
+func TestMapConfigValidate_Presets(t *testing.T) {
+	registry := NewMapConfigRegistry()
+	for _, id := range registry.List() {
+		config, _ := registry.Get(id)
+		if err := config.Validate(); err != nil {
+			t.Errorf("preset %s failed validation: %v", id, err)
+		}
+	}
+}
+
+func TestMapConfigValidate_Rejects(t *testing.T) {
+	cases := map[string]func(c *MapConfig){
+		"unknown size":       func(c *MapConfig) { c.Size = "gigantic" },
+		"custom too large":   func(c *MapConfig) { c.CustomSize = MaxMapSize + 1 },
+		"too many octaves":   func(c *MapConfig) { c.ElevationNoise.Octaves = MaxNoiseOctaves + 1 },
+		"no biomes":          func(c *MapConfig) { c.BiomeDistributions = nil },
+		"unknown biome":      func(c *MapConfig) { c.BiomeDistributions[0].Biome = "lava_lamp" },
+		"inverted range":     func(c *MapConfig) { c.BiomeDistributions[0].ElevationMin = 0.9 },
+		"border eats map":    func(c *MapConfig) { c.CustomSize = 64; c.OceanBorderWidth = 32 },
+		"negative dungeons":  func(c *MapConfig) { c.Structures.DungeonsPerMap = -1 },
+		"lake chance over 1": func(c *MapConfig) { c.LakeChance = 2 },
+	}
+
+	for name, mutate := range cases {
+		config := DefaultMapConfig()
+		mutate(config)
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Anthropic API defaults
+const (
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+)
+
+// AnthropicClient implements game.LLMClient for Anthropic's messages API
+type AnthropicClient struct {
+	apiKey     string
+	model      string
+	sampling   sampling
+	prices     map[string]config.ModelPrice // For cost estimates
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewAnthropicClient creates a messages API client
+func NewAnthropicClient(baseURL, apiKey, model string, timeout time.Duration) *AnthropicClient {
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+	return &AnthropicClient{
+		apiKey:   apiKey,
+		model:    model,
+		sampling: defaultSampling(),
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// AnthropicRequest represents a messages API request
+type AnthropicRequest struct {
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	Temperature float64              `json:"temperature"`
+	TopP        *float64             `json:"top_p,omitempty"`
+	Messages    []AnthropicMessage   `json:"messages"`
+	Tools       []AnthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *AnthropicToolChoice `json:"tool_choice,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
+}
+
+// AnthropicMessage is one conversation turn
+type AnthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// AnthropicTool declares a tool the model can use
+type AnthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// AnthropicToolChoice forces the model to use a specific tool
+type AnthropicToolChoice struct {
+	Type string `json:"type"` // "tool"
+	Name string `json:"name"`
+}
+
+// AnthropicContentBlock is one block of a response: text or a tool use
+type AnthropicContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`  // tool_use only
+	Input json.RawMessage `json:"input,omitempty"` // tool_use only
+}
+
+// anthropicUsage is the token usage of a message
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicError is an error returned in a response body or stream
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// AnthropicResponse represents a messages API response
+type AnthropicResponse struct {
+	Content []AnthropicContentBlock `json:"content"`
+	Usage   *anthropicUsage         `json:"usage,omitempty"`
+	Error   *anthropicError         `json:"error,omitempty"`
+}
+
+// AnthropicStreamEvent is one event of a streamed message. Input tokens
+// arrive with message_start and output tokens with the final message_delta.
+type AnthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	Message      *AnthropicResponse     `json:"message,omitempty"`       // message_start
+	ContentBlock *AnthropicContentBlock `json:"content_block,omitempty"` // content_block_start
+	Delta        *struct {
+		Type        string `json:"type"` // text_delta or input_json_delta
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta,omitempty"`
+	Usage *anthropicUsage `json:"usage,omitempty"` // message_delta
+	Error *anthropicError `json:"error,omitempty"`
+}
+
+// GetAction sends a prompt to the messages API and returns the parsed action
+func (c *AnthropicClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	start := time.Now()
+	model, params := requestParams(ctx, c.model, c.sampling)
+	action, err := c.getAction(ctx, agentID, prompt, model, params)
+	observeRequest(model, time.Since(start), err)
+	return action, err
+}
+
+func (c *AnthropicClient) getAction(ctx context.Context, agentID uuid.UUID, prompt, model string, params sampling) (game.Action, error) {
+	logger := slog.With("agent_id", agentID, "model", model)
+	req := AnthropicRequest{
+		Model:       model,
+		MaxTokens:   params.maxTokens,
+		Temperature: params.temperature,
+		TopP:        params.topP,
+		Messages:    []AnthropicMessage{{Role: "user", Content: prompt}},
+	}
+	if schema, ok := game.ActionSchemaFromContext(ctx); ok {
+		req.Tools = []AnthropicTool{{Name: ActionToolName, Description: actionToolDescription, InputSchema: schema}}
+		req.ToolChoice = &AnthropicToolChoice{Type: "tool", Name: ActionToolName}
+	}
+
+	var blocks []AnthropicContentBlock
+	var err error
+	if stream, ok := game.ThinkingStreamFromContext(ctx); ok {
+		blocks, err = c.messageStream(ctx, logger, req, newReasoningStream(stream).write)
+	} else {
+		blocks, err = c.message(ctx, logger, req)
+	}
+	if err != nil {
+		return game.WaitAction(agentID), err
+	}
+	for _, block := range blocks {
+		if block.Type == "tool_use" && block.Name == ActionToolName {
+			return parseToolArguments(agentID, string(block.Input))
+		}
+	}
+	return ParseActionFromText(agentID, anthropicText(blocks))
+}
+
+// GenerateText sends a free-form prompt and returns the reply text
+func (c *AnthropicClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	start := time.Now()
+	blocks, err := c.message(ctx, slog.With("model", c.model), AnthropicRequest{
+		Model:       c.model,
+		MaxTokens:   512,
+		Temperature: 0.9,
+		Messages:    []AnthropicMessage{{Role: "user", Content: prompt}},
+	})
+	observeRequest(c.model, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	text := anthropicText(blocks)
+	if text == "" {
+		return "", fmt.Errorf("empty response from API")
+	}
+	return text, nil
+}
+
+// message sends one messages API request and returns the content blocks
+func (c *AnthropicClient) message(ctx context.Context, logger *slog.Logger, reqBody AnthropicRequest) ([]AnthropicContentBlock, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("no API key configured")
+	}
+
+	body, err := postJSON(ctx, c.httpClient, logger, c.baseURL+"/v1/messages", c.headers(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var anthropicResp AnthropicResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		logger.Error("LLM response parse failed", "error", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if anthropicResp.Error != nil {
+		logger.Error("LLM API returned error", "error_type", anthropicResp.Error.Type, "error_message", anthropicResp.Error.Message)
+		return nil, fmt.Errorf("API error: %s", anthropicResp.Error.Message)
+	}
+
+	if usage := anthropicResp.Usage; usage != nil {
+		reportUsage(ctx, c.prices, reqBody.Model, usage.InputTokens, usage.OutputTokens)
+	}
+
+	if len(anthropicResp.Content) == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return anthropicResp.Content, nil
+}
+
+// messageStream streams one messages API request, passing text and tool
+// input deltas to onText, and returns the assembled content blocks
+func (c *AnthropicClient) messageStream(ctx context.Context, logger *slog.Logger, reqBody AnthropicRequest, onText func(string)) ([]AnthropicContentBlock, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("no API key configured")
+	}
+	reqBody.Stream = true
+
+	var blocks []AnthropicContentBlock
+	var inputs []strings.Builder // Streamed tool_use input per block
+	var usage anthropicUsage
+	err := postStream(ctx, c.httpClient, logger, c.baseURL+"/v1/messages", c.headers(), reqBody, func(_ string, data []byte) error {
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			logger.Error("LLM stream parse failed", "error", err)
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+
+		switch event.Type {
+		case "error":
+			if event.Error == nil {
+				return fmt.Errorf("API error")
+			}
+			logger.Error("LLM API returned error", "error_type", event.Error.Type, "error_message", event.Error.Message)
+			return fmt.Errorf("API error: %s", event.Error.Message)
+		case "message_start":
+			if event.Message != nil && event.Message.Usage != nil {
+				usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.Index == len(blocks) {
+				blocks = append(blocks, *event.ContentBlock)
+				inputs = append(inputs, strings.Builder{})
+			}
+		case "content_block_delta":
+			if event.Delta == nil || event.Index < 0 || event.Index >= len(blocks) {
+				return nil
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				blocks[event.Index].Text += event.Delta.Text
+				onText(event.Delta.Text)
+			case "input_json_delta":
+				inputs[event.Index].WriteString(event.Delta.PartialJSON)
+				onText(event.Delta.PartialJSON)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reportUsage(ctx, c.prices, reqBody.Model, usage.InputTokens, usage.OutputTokens)
+	for i := range blocks {
+		if inputs[i].Len() > 0 {
+			blocks[i].Input = json.RawMessage(inputs[i].String())
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return blocks, nil
+}
+
+// headers returns the auth and version headers
+func (c *AnthropicClient) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+// anthropicText concatenates the text blocks of a response
+func anthropicText(blocks []AnthropicContentBlock) string {
+	var text strings.Builder
+	for _, block := range blocks {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
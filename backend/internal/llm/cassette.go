@@ -0,0 +1,331 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Cassette entry kinds
+const (
+	CassetteAction = "action"
+	CassetteText   = "text"
+)
+
+// ErrCassetteMiss is returned when a replayed prompt has no recorded response
+var ErrCassetteMiss = errors.New("no recorded response for prompt")
+
+// uuidPattern matches the IDs that differ between otherwise identical games
+var uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// CassetteEntry is one recorded LLM exchange. IDs are replaced by
+// placeholders numbered in order of appearance in the prompt, so a replayed
+// game with fresh IDs hashes the same prompts and gets responses that refer
+// to its own agents.
+type CassetteEntry struct {
+	Kind       string `json:"kind"`            // action or text
+	Agent      string `json:"agent,omitempty"` // Agent ID at recording time
+	PromptHash string `json:"prompt_hash"`
+	Response   string `json:"response,omitempty"` // Raw response with IDs masked
+	Error      string `json:"error,omitempty"`    // Set when the request failed without a response
+}
+
+// RecordingClient passes requests to another client and appends every
+// exchange to a cassette file as JSON lines
+type RecordingClient struct {
+	client game.LLMClient
+
+	mu  sync.Mutex
+	out io.WriteCloser
+	enc *json.Encoder
+}
+
+// NewRecordingClient creates a recorder that writes the cassette to path
+func NewRecordingClient(client game.LLMClient, path string) (*RecordingClient, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	return &RecordingClient{client: client, out: f, enc: json.NewEncoder(f)}, nil
+}
+
+// GetAction requests an action from the wrapped client and records it
+func (c *RecordingClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	action, err := c.client.GetAction(ctx, agentID, prompt)
+
+	ids := promptIDs(prompt)
+	response := action.Raw
+	if response == "" && err == nil {
+		response = actionJSON(action) // Clients without a raw response, e.g. the mock
+	}
+	entry := CassetteEntry{
+		Kind:       CassetteAction,
+		Agent:      agentID.String(),
+		PromptHash: hashPrompt(prompt, ids),
+		Response:   maskIDs(response, ids),
+	}
+	if err != nil && response == "" {
+		entry.Error = err.Error()
+	}
+	c.record(entry)
+	return action, err
+}
+
+// GenerateText requests text from the wrapped client and records it
+func (c *RecordingClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	gen, ok := c.client.(game.TextGenerator)
+	if !ok {
+		return "", fmt.Errorf("LLM client can't generate text")
+	}
+	text, err := gen.GenerateText(ctx, prompt)
+
+	ids := promptIDs(prompt)
+	entry := CassetteEntry{
+		Kind:       CassetteText,
+		PromptHash: hashPrompt(prompt, ids),
+		Response:   maskIDs(text, ids),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.record(entry)
+	return text, err
+}
+
+// ValidateModel defers to the wrapped client
+func (c *RecordingClient) ValidateModel(settings game.ModelSettings) error {
+	if validator, ok := c.client.(game.ModelValidator); ok {
+		return validator.ValidateModel(settings)
+	}
+	return nil
+}
+
+// Close flushes and closes the cassette file
+func (c *RecordingClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out.Close()
+}
+
+// record appends an entry to the cassette
+func (c *RecordingClient) record(entry CassetteEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(entry); err != nil {
+		slog.Warn("Failed to record LLM response", "error", err)
+	}
+}
+
+// ReplayClient serves responses from a cassette instead of calling an LLM.
+// A prompt is matched to an unused entry with the same hash, preferring one
+// recorded for the same agent ID; each entry is served once, in recorded
+// order.
+type ReplayClient struct {
+	mu      sync.Mutex
+	entries []CassetteEntry
+	used    []bool
+	byHash  map[string][]int
+}
+
+// NewReplayClient loads a cassette file
+func NewReplayClient(path string) (*ReplayClient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := ReadCassette(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayClientWithEntries(entries), nil
+}
+
+// NewReplayClientWithEntries creates a replay client from loaded entries
+func NewReplayClientWithEntries(entries []CassetteEntry) *ReplayClient {
+	c := &ReplayClient{
+		entries: entries,
+		used:    make([]bool, len(entries)),
+		byHash:  make(map[string][]int),
+	}
+	for i, entry := range entries {
+		key := entry.Kind + ":" + entry.PromptHash
+		c.byHash[key] = append(c.byHash[key], i)
+	}
+	return c
+}
+
+// ReadCassette parses cassette JSON lines
+func ReadCassette(r io.Reader) ([]CassetteEntry, error) {
+	var entries []CassetteEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry CassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid cassette entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	return entries, nil
+}
+
+// GetAction replays the recorded response for the prompt
+func (c *ReplayClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	ids := promptIDs(prompt)
+	entry, ok := c.take(CassetteAction, agentID.String(), hashPrompt(prompt, ids))
+	if !ok {
+		return game.WaitAction(agentID), ErrCassetteMiss
+	}
+	if entry.Response == "" {
+		return game.WaitAction(agentID), errors.New(entry.Error)
+	}
+	return ParseActionFromText(agentID, unmaskIDs(entry.Response, ids))
+}
+
+// GenerateText replays the recorded text for the prompt
+func (c *ReplayClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	ids := promptIDs(prompt)
+	entry, ok := c.take(CassetteText, "", hashPrompt(prompt, ids))
+	if !ok {
+		return "", ErrCassetteMiss
+	}
+	if entry.Error != "" {
+		return "", errors.New(entry.Error)
+	}
+	return unmaskIDs(entry.Response, ids), nil
+}
+
+// Remaining returns the number of entries not yet served
+func (c *ReplayClient) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, used := range c.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// take marks and returns the next unused entry for a prompt hash
+func (c *ReplayClient) take(kind, agent, hash string) (CassetteEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	match := -1
+	for _, i := range c.byHash[kind+":"+hash] {
+		if c.used[i] {
+			continue
+		}
+		if c.entries[i].Agent == agent {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return CassetteEntry{}, false
+	}
+	c.used[match] = true
+	return c.entries[match], true
+}
+
+// actionJSON encodes an action in the flat format game.ParseAction reads
+func actionJSON(action game.Action) string {
+	flat := struct {
+		Action      game.ActionType `json:"action"`
+		Direction   game.Direction  `json:"direction,omitempty"`
+		Steps       int             `json:"steps,omitempty"`
+		Target      string          `json:"target,omitempty"`
+		Message     string          `json:"message,omitempty"`
+		ItemID      string          `json:"item_id,omitempty"`
+		UpgradeType string          `json:"upgrade_type,omitempty"`
+		Reasoning   string          `json:"reasoning,omitempty"`
+	}{
+		Action:      action.Type,
+		Direction:   action.Params.Direction,
+		Steps:       action.Params.Steps,
+		Message:     action.Params.Message,
+		ItemID:      action.Params.ItemID,
+		UpgradeType: action.Params.UpgradeType,
+		Reasoning:   action.Reasoning,
+	}
+	if action.Params.Target != nil {
+		flat.Target = action.Params.Target.String()
+	}
+	data, _ := json.Marshal(flat)
+	return string(data)
+}
+
+// promptIDs returns the distinct IDs in a prompt in order of appearance
+func promptIDs(prompt string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range uuidPattern.FindAllString(prompt, -1) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// placeholder returns the masked form of the nth prompt ID
+func placeholder(n int) string {
+	return "<id" + strconv.Itoa(n) + ">"
+}
+
+// hashPrompt hashes a prompt with its IDs masked
+func hashPrompt(prompt string, ids []string) string {
+	sum := sha256.Sum256([]byte(maskIDs(prompt, ids)))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskIDs replaces prompt IDs in text with placeholders
+func maskIDs(text string, ids []string) string {
+	if len(ids) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(ids))
+	for i, id := range ids {
+		pairs = append(pairs, id, placeholder(i))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// unmaskIDs replaces placeholders in text with the current prompt's IDs
+func unmaskIDs(text string, ids []string) string {
+	if len(ids) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(ids))
+	for i, id := range ids {
+		pairs = append(pairs, placeholder(i), id)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
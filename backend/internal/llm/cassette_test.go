@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// clientFunc adapts a function to game.LLMClient
+type clientFunc func(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error)
+
+func (f clientFunc) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	return f(ctx, agentID, prompt)
+}
+
+func TestCassette_ReplaysWithFreshIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	fightFirstEnemy := clientFunc(func(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+		target := promptIDs(prompt)[0]
+		return ParseActionFromText(agentID, `{"action": "FIGHT", "target": "`+target+`"}`)
+	})
+	prompt := func(enemy uuid.UUID) string {
+		return "Nearby agents:\n- Bob at (1, 2) HP 3/3 [ID: " + enemy.String() + "]\n"
+	}
+
+	recorder, err := NewRecordingClient(fightFirstEnemy, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recorder.GetAction(context.Background(), uuid.New(), prompt(uuid.New())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	replay, err := NewReplayClient(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	enemy := uuid.New()
+	action, err := replay.GetAction(context.Background(), uuid.New(), prompt(enemy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionFight || action.Params.Target == nil || *action.Params.Target != enemy {
+		t.Errorf("expected FIGHT against this game's enemy %v, got %+v", enemy, action)
+	}
+
+	if _, err := replay.GetAction(context.Background(), uuid.New(), prompt(enemy)); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("expected each entry to be served once, got %v", err)
+	}
+}
+
+func TestReplayClient_PrefersSameAgent(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	hash := hashPrompt("same prompt", nil)
+	replay := NewReplayClientWithEntries([]CassetteEntry{
+		{Kind: CassetteAction, Agent: alice.String(), PromptHash: hash, Response: `{"action": "CLAIM"}`},
+		{Kind: CassetteAction, Agent: bob.String(), PromptHash: hash, Response: `{"action": "WAIT"}`},
+		{Kind: CassetteAction, Agent: bob.String(), PromptHash: hash, Error: "timeout"},
+	})
+
+	if action, _ := replay.GetAction(context.Background(), bob, "same prompt"); action.Type != game.ActionWait {
+		t.Errorf("expected bob's recorded WAIT, got %s", action.Type)
+	}
+	if _, err := replay.GetAction(context.Background(), bob, "same prompt"); err == nil || err.Error() != "timeout" {
+		t.Errorf("expected bob's recorded error, got %v", err)
+	}
+	if action, _ := replay.GetAction(context.Background(), uuid.New(), "same prompt"); action.Type != game.ActionClaim {
+		t.Errorf("expected an unknown agent to get the remaining entry, got %s", action.Type)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("expected cassette to be used up, %d left", replay.Remaining())
+	}
+}
+
+func TestActionJSON_RoundTrip(t *testing.T) {
+	agentID := uuid.New()
+	want := game.MoveAction(agentID, game.DirEast)
+	got, err := ParseActionFromText(agentID, actionJSON(want))
+	if err != nil || got.Type != want.Type || got.Params.Direction != want.Params.Direction {
+		t.Errorf("expected %+v back, got %+v, %v", want.Params, got.Params, err)
+	}
+}
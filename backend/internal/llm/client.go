@@ -1,16 +1,17 @@
 package llm
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
 	"github.com/lucas/promptlands/internal/game"
 )
 
@@ -21,6 +22,8 @@ import (
 type GeminiClient struct {
 	apiKey     string
 	model      string
+	sampling   sampling
+	prices     map[string]config.ModelPrice // For cost estimates
 	timeout    time.Duration
 	httpClient *http.Client
 	baseURL    string
@@ -29,9 +32,10 @@ type GeminiClient struct {
 // NewGeminiClient creates a new Gemini API client
 func NewGeminiClient(apiKey, model string, timeout time.Duration) *GeminiClient {
 	return &GeminiClient{
-		apiKey:  apiKey,
-		model:   model,
-		timeout: timeout,
+		apiKey:   apiKey,
+		model:    model,
+		sampling: defaultSampling(),
+		timeout:  timeout,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
@@ -57,9 +61,11 @@ type GeminiPart struct {
 
 // GeminiGenerationConfig configures generation parameters
 type GeminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
-	ResponseMimeType string `json:"responseMimeType,omitempty"`
+	Temperature      float64                `json:"temperature"`
+	TopP             *float64               `json:"topP,omitempty"`
+	MaxOutputTokens  int                    `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string                 `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]interface{} `json:"responseSchema,omitempty"` // Constrains JSON output
 }
 
 // GeminiResponse represents the response from Gemini API
@@ -71,6 +77,10 @@ type GeminiResponse struct {
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
@@ -79,95 +89,145 @@ type GeminiResponse struct {
 
 // GetAction sends a prompt to Gemini and returns the parsed action
 func (c *GeminiClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
-	if c.apiKey == "" {
-		return game.WaitAction(agentID), fmt.Errorf("no API key configured")
-	}
-
-	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.baseURL, c.model, c.apiKey)
+	start := time.Now()
+	model, params := requestParams(ctx, c.model, c.sampling)
+	action, err := c.getAction(ctx, agentID, prompt, model, params)
+	observeRequest(model, time.Since(start), err)
+	return action, err
+}
 
-	reqBody := GeminiRequest{
-		Contents: []GeminiContent{
-			{
-				Parts: []GeminiPart{
-					{Text: prompt},
-				},
-			},
-		},
-		GenerationConfig: GeminiGenerationConfig{
-			Temperature:      0.7,
-			MaxOutputTokens:  256,
-			ResponseMimeType: "application/json",
-		},
+func (c *GeminiClient) getAction(ctx context.Context, agentID uuid.UUID, prompt, model string, params sampling) (game.Action, error) {
+	logger := slog.With("agent_id", agentID, "model", model)
+	genConfig := GeminiGenerationConfig{
+		Temperature:      params.temperature,
+		TopP:             params.topP,
+		MaxOutputTokens:  params.maxTokens,
+		ResponseMimeType: "application/json",
 	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return game.WaitAction(agentID), fmt.Errorf("failed to marshal request: %w", err)
+	if schema, ok := game.ActionSchemaFromContext(ctx); ok {
+		genConfig.ResponseSchema = geminiSchema(schema)
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
-	if err != nil {
-		return game.WaitAction(agentID), fmt.Errorf("failed to create request: %w", err)
+	var responseText string
+	var err error
+	if stream, ok := game.ThinkingStreamFromContext(ctx); ok {
+		responseText, err = c.generateStream(ctx, logger, model, prompt, genConfig, newReasoningStream(stream).write)
+	} else {
+		responseText, err = c.generate(ctx, logger, model, prompt, genConfig)
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		slog.Error("LLM request failed", "agent_id", agentID, "model", c.model, "error", err)
-		return game.WaitAction(agentID), fmt.Errorf("request failed: %w", err)
+		return game.WaitAction(agentID), err
 	}
-	defer resp.Body.Close()
+	return parseToolArguments(agentID, responseText)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		slog.Error("LLM response read failed", "agent_id", agentID, "model", c.model, "error", err)
-		return game.WaitAction(agentID), fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := resp.Header.Get("Retry-After")
-		slog.Warn("LLM rate limited",
-			"agent_id", agentID,
-			"model", c.model,
-			"status", resp.StatusCode,
-			"retry_after", retryAfter,
-			"body", string(body),
-		)
-		return game.WaitAction(agentID), fmt.Errorf("rate limited (status 429, retry-after: %s): %s", retryAfter, string(body))
+// GenerateText sends a free-form prompt to Gemini and returns the reply text
+func (c *GeminiClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	start := time.Now()
+	text, err := c.generate(ctx, slog.With("model", c.model), c.model, prompt, GeminiGenerationConfig{
+		Temperature:     0.9,
+		MaxOutputTokens: 512,
+	})
+	observeRequest(c.model, time.Since(start), err)
+	return text, err
+}
+
+// generate sends one generateContent request and returns the first candidate's text
+func (c *GeminiClient) generate(ctx context.Context, logger *slog.Logger, model, prompt string, genConfig GeminiGenerationConfig) (string, error) {
+	if c.apiKey == "" {
+		return "", fmt.Errorf("no API key configured")
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("LLM API error",
-			"agent_id", agentID,
-			"model", c.model,
-			"status", resp.StatusCode,
-			"body", string(body),
-		)
-		return game.WaitAction(agentID), fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.baseURL, neturl.PathEscape(model), c.apiKey)
+
+	body, err := postJSON(ctx, c.httpClient, logger, url, nil, geminiRequest(prompt, genConfig))
+	if err != nil {
+		return "", err
 	}
 
 	var geminiResp GeminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		slog.Error("LLM response parse failed", "agent_id", agentID, "model", c.model, "error", err)
-		return game.WaitAction(agentID), fmt.Errorf("failed to parse response: %w", err)
+		logger.Error("LLM response parse failed", "error", err)
+		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if geminiResp.Error != nil {
-		slog.Error("LLM API returned error",
-			"agent_id", agentID,
-			"model", c.model,
+		logger.Error("LLM API returned error",
 			"error_code", geminiResp.Error.Code,
 			"error_message", geminiResp.Error.Message,
 		)
-		return game.WaitAction(agentID), fmt.Errorf("API error: %s", geminiResp.Error.Message)
+		return "", fmt.Errorf("API error: %s", geminiResp.Error.Message)
+	}
+
+	if usage := geminiResp.UsageMetadata; usage != nil {
+		reportUsage(ctx, c.prices, model, usage.PromptTokenCount, usage.CandidatesTokenCount)
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return game.WaitAction(agentID), fmt.Errorf("empty response from API")
+		return "", fmt.Errorf("empty response from API")
+	}
+
+	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+}
+
+// generateStream streams one streamGenerateContent request, passing text
+// deltas to onText, and returns the full text of the first candidate
+func (c *GeminiClient) generateStream(ctx context.Context, logger *slog.Logger, model, prompt string, genConfig GeminiGenerationConfig, onText func(string)) (string, error) {
+	if c.apiKey == "" {
+		return "", fmt.Errorf("no API key configured")
+	}
+
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse&key=%s", c.baseURL, neturl.PathEscape(model), c.apiKey)
+
+	var text strings.Builder
+	var promptTokens, completionTokens int
+	err := postStream(ctx, c.httpClient, logger, url, nil, geminiRequest(prompt, genConfig), func(_ string, data []byte) error {
+		var chunk GeminiResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			logger.Error("LLM stream parse failed", "error", err)
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			logger.Error("LLM API returned error",
+				"error_code", chunk.Error.Code,
+				"error_message", chunk.Error.Message,
+			)
+			return fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if usage := chunk.UsageMetadata; usage != nil {
+			// Every chunk carries running totals; the last one wins
+			promptTokens, completionTokens = usage.PromptTokenCount, usage.CandidatesTokenCount
+		}
+		if len(chunk.Candidates) > 0 {
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				text.WriteString(part.Text)
+				onText(part.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	responseText := geminiResp.Candidates[0].Content.Parts[0].Text
-	return game.ParseAction(agentID, []byte(responseText))
+	reportUsage(ctx, c.prices, model, promptTokens, completionTokens)
+	if text.Len() == 0 {
+		return "", fmt.Errorf("empty response from API")
+	}
+	return text.String(), nil
+}
+
+// geminiRequest builds a single-turn request for prompt
+func geminiRequest(prompt string, genConfig GeminiGenerationConfig) GeminiRequest {
+	return GeminiRequest{
+		Contents: []GeminiContent{
+			{
+				Parts: []GeminiPart{
+					{Text: prompt},
+				},
+			},
+		},
+		GenerationConfig: genConfig,
+	}
 }
 
 // MockClient is a mock LLM client for testing
@@ -196,3 +256,23 @@ func (c *MockClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt st
 	action.ReceivedAt = time.Now()
 	return action, nil
 }
+
+// mockCommentary is the canned narrator output used by MockClient
+var mockCommentary = []string{
+	"The borders shift again as the agents jostle for every last tile!",
+	"A bold move on the frontier - the crowd did not see that coming.",
+	"Quiet on the surface, but you can feel the tension building across the map.",
+	"Territory changes hands and the standings tighten once more!",
+	"Somebody is playing the long game here, and it is starting to pay off.",
+}
+
+// mockRecap is the canned end-of-game recap used by MockClient
+const mockRecap = "What a match! Fortunes rose and fell across the map, but in the end one agent held the most ground and claimed victory."
+
+// GenerateText returns canned commentary so the narrator works offline
+func (c *MockClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	if strings.Contains(prompt, game.RecapPromptMarker) {
+		return mockRecap, nil
+	}
+	return mockCommentary[int(time.Now().UnixNano())%len(mockCommentary)], nil
+}
@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// newTestServer serves a fixed response and passes each decoded request to check
+func newTestServer(t *testing.T, status int, response string, check func(r *http.Request, body map[string]interface{})) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if check != nil {
+			check(r, body)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAIClient_GetAction(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK,
+		`{"choices":[{"message":{"role":"assistant","content":"`+"```json\\n{\\\"action\\\":\\\"CLAIM\\\"}\\n```"+`"}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if r.URL.Path != "/v1/chat/completions" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+				t.Errorf("unexpected Authorization header %q", got)
+			}
+			if body["model"] != "gpt-test" {
+				t.Errorf("unexpected model %v", body["model"])
+			}
+		})
+
+	client := NewOpenAIClient(srv.URL+"/v1/", "sk-test", "gpt-test", time.Second)
+	agentID := uuid.New()
+	action, err := client.GetAction(context.Background(), agentID, "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionClaim || action.AgentID != agentID {
+		t.Errorf("expected CLAIM for the agent, got %+v", action)
+	}
+	if action.Raw == "" {
+		t.Error("expected raw response to be kept")
+	}
+}
+
+func TestOpenAIClient_NoKeyForLocalServers(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"Nice move!"}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if got := r.Header.Get("Authorization"); got != "" {
+				t.Errorf("expected no Authorization header, got %q", got)
+			}
+		})
+
+	client := NewOpenAIClient(srv.URL, "", "llama3", time.Second)
+	text, err := client.GenerateText(context.Background(), "prompt")
+	if err != nil || text != "Nice move!" {
+		t.Errorf("expected reply text, got %q, %v", text, err)
+	}
+}
+
+func TestAnthropicClient_GetAction(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK,
+		`{"content":[{"type":"text","text":"{\"action\":\"MOVE\",\"direction\":\"north\"}"}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if r.URL.Path != "/v1/messages" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+				t.Errorf("missing auth headers: %v", r.Header)
+			}
+			if body["max_tokens"] == nil {
+				t.Error("expected max_tokens to be set")
+			}
+		})
+
+	client := NewAnthropicClient(srv.URL, "key", "claude-test", time.Second)
+	action, err := client.GetAction(context.Background(), uuid.New(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionMove || action.Params.Direction != game.DirNorth {
+		t.Errorf("expected MOVE N, got %+v", action)
+	}
+}
+
+func TestClients_RateLimited(t *testing.T) {
+	srv := newTestServer(t, http.StatusTooManyRequests, `{"error":{"message":"slow down"}}`, nil)
+
+	clients := map[string]game.LLMClient{
+		"gemini":    &GeminiClient{apiKey: "key", model: "m", httpClient: srv.Client(), baseURL: srv.URL},
+		"openai":    NewOpenAIClient(srv.URL, "key", "m", time.Second),
+		"anthropic": NewAnthropicClient(srv.URL, "key", "m", time.Second),
+	}
+	for name, client := range clients {
+		agentID := uuid.New()
+		action, err := client.GetAction(context.Background(), agentID, "prompt")
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("%s: expected ErrRateLimited, got %v", name, err)
+		}
+		if action.Type != game.ActionWait || action.AgentID != agentID {
+			t.Errorf("%s: expected WAIT fallback, got %+v", name, action)
+		}
+	}
+}
+
+func TestClients_SendZeroTemperature(t *testing.T) {
+	var bodies []map[string]interface{}
+	openai := newTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"{\"action\":\"WAIT\"}"}}]}`,
+		func(r *http.Request, body map[string]interface{}) { bodies = append(bodies, body) })
+	anthropic := newTestServer(t, http.StatusOK, `{"content":[{"type":"text","text":"{\"action\":\"WAIT\"}"}]}`,
+		func(r *http.Request, body map[string]interface{}) { bodies = append(bodies, body) })
+
+	zero := 0.0
+	ctx := game.WithModelSettings(context.Background(), game.ModelSettings{Temperature: &zero})
+	NewOpenAIClient(openai.URL, "", "gpt-test", time.Second).GetAction(ctx, uuid.New(), "prompt")
+	NewAnthropicClient(anthropic.URL, "key", "claude-test", time.Second).GetAction(ctx, uuid.New(), "prompt")
+
+	// A zero in the server config is a setting too, not "unset"
+	configured, _ := NewClient(config.LLMConfig{Provider: ProviderOpenAI, BaseURL: openai.URL, Model: "m", Temperature: &zero})
+	configured.GetAction(context.Background(), uuid.New(), "prompt")
+
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if temperature, ok := body["temperature"]; !ok || temperature != 0.0 {
+			t.Errorf("request %d: expected \"temperature\":0 in body, got %v", i, body["temperature"])
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	cases := []struct {
+		provider string
+		want     string
+	}{
+		{"", "*llm.GeminiClient"},
+		{ProviderGemini, "*llm.GeminiClient"},
+		{ProviderOpenAI, "*llm.OpenAIClient"},
+		{ProviderOllama, "*llm.OpenAIClient"},
+		{ProviderAnthropic, "*llm.AnthropicClient"},
+	}
+	for _, c := range cases {
+		client, err := NewClient(config.LLMConfig{Provider: c.provider, Model: "m"})
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.provider, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", client); got != c.want {
+			t.Errorf("%q: expected %s, got %s", c.provider, c.want, got)
+		}
+	}
+
+	if client, _ := NewClient(config.LLMConfig{Provider: ProviderOllama, Model: "m"}); client.(*OpenAIClient).baseURL != DefaultOllamaBaseURL {
+		t.Errorf("expected ollama to default to %s", DefaultOllamaBaseURL)
+	}
+	if _, err := NewClient(config.LLMConfig{Provider: "bogus", Model: "m"}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestOpenAIClient_ReportsUsage(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK,
+		`{"choices":[{"message":{"content":"{\"action\":\"WAIT\"}"}}],"usage":{"prompt_tokens":2000,"completion_tokens":100}}`, nil)
+
+	client := NewOpenAIClient(srv.URL, "", "gpt-4o-2024-08-06", time.Second)
+	client.prices = map[string]config.ModelPrice{"gpt-4o": {Input: 2.5, Output: 10}, "gpt": {Input: 100, Output: 100}}
+
+	var got []game.TokenUsage
+	ctx := game.WithUsageReporter(context.Background(), func(u game.TokenUsage) { got = append(got, u) })
+	if _, err := client.GetAction(ctx, uuid.New(), "prompt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].PromptTokens != 2000 || got[0].CompletionTokens != 100 {
+		t.Fatalf("expected reported token usage, got %+v", got)
+	}
+	if want := 0.006; got[0].CostUSD < want-1e-9 || got[0].CostUSD > want+1e-9 {
+		t.Errorf("expected cost %v from the longest matching price, got %v", want, got[0].CostUSD)
+	}
+}
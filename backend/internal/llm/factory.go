@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"fmt"
+
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Supported values of llm.provider
+const (
+	ProviderGemini    = "gemini"
+	ProviderOpenAI    = "openai"
+	ProviderOllama    = "ollama"
+	ProviderAnthropic = "anthropic"
+)
+
+// NewClient builds the LLM client selected by cfg.Provider. An empty
+// provider means Gemini.
+func NewClient(cfg config.LLMConfig) (game.LLMClient, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("llm.model is required")
+	}
+
+	switch cfg.Provider {
+	case "", ProviderGemini:
+		client := NewGeminiClient(cfg.APIKey, cfg.Model, cfg.Timeout)
+		client.sampling.configure(cfg)
+		client.prices = cfg.Prices
+		if cfg.BaseURL != "" {
+			client.baseURL = cfg.BaseURL
+		}
+		return client, nil
+	case ProviderOpenAI:
+		client := NewOpenAIClient(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Timeout)
+		client.sampling.configure(cfg)
+		client.prices = cfg.Prices
+		client.tools = !cfg.DisableTools
+		return client, nil
+	case ProviderOllama:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = DefaultOllamaBaseURL
+		}
+		client := NewOpenAIClient(baseURL, cfg.APIKey, cfg.Model, cfg.Timeout)
+		client.sampling.configure(cfg)
+		client.prices = cfg.Prices
+		client.tools = !cfg.DisableTools
+		return client, nil
+	case ProviderAnthropic:
+		client := NewAnthropicClient(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Timeout)
+		client.sampling.configure(cfg)
+		client.prices = cfg.Prices
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown llm.provider %q (want gemini, openai, ollama or anthropic)", cfg.Provider)
+	}
+}
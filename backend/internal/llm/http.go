@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitError is returned for HTTP 429 responses. errors.Is matches it
+// against ErrRateLimited.
+type RateLimitError struct {
+	RetryAfter time.Duration // Zero when the server didn't say
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v (status 429, retry-after: %s): %s", ErrRateLimited, e.RetryAfter, e.Body)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) true
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// APIError is returned for other non-200 responses
+type APIError struct {
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.Status, e.Body)
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or HTTP-date form
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// postJSON sends a JSON request and returns the body of a 200 response.
+// 429 responses return a *RateLimitError; other statuses an *APIError.
+func postJSON(ctx context.Context, client *http.Client, logger *slog.Logger, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	resp, err := sendJSON(ctx, client, logger, url, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("LLM response read failed", "error", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// sendJSON sends a JSON request and returns a 200 response for the caller
+// to read and close. Other statuses are turned into errors as in postJSON.
+func sendJSON(ctx context.Context, client *http.Client, logger *slog.Logger, url string, headers map[string]string, payload interface{}) (*http.Response, error) {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("LLM request failed", "error", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("LLM response read failed", "error", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := resp.Header.Get("Retry-After")
+		logger.Warn("LLM rate limited",
+			"status", resp.StatusCode,
+			"retry_after", retryAfter,
+			"body", string(body),
+		)
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(retryAfter, time.Now()), Body: string(body)}
+	}
+
+	logger.Error("LLM API error",
+		"status", resp.StatusCode,
+		"body", string(body),
+	)
+	return nil, &APIError{Status: resp.StatusCode, Body: string(body)}
+}
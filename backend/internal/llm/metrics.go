@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/lucas/promptlands/internal/metrics"
+)
+
+// ErrRateLimited matches errors for requests rejected with HTTP 429 or by
+// the scheduler's rate limits
+var ErrRateLimited = errors.New("rate limited")
+
+var (
+	requestDuration = metrics.Default.NewHistogram("promptlands_llm_request_duration_seconds",
+		"LLM request latency.", nil, "model")
+	requestsTotal = metrics.Default.NewCounter("promptlands_llm_requests_total",
+		"LLM requests sent.", "model")
+	requestErrors = metrics.Default.NewCounter("promptlands_llm_errors_total",
+		"Failed LLM requests by reason (error, timeout, rate_limited).", "model", "reason")
+)
+
+// observeRequest records the outcome of one LLM request
+func observeRequest(model string, latency time.Duration, err error) {
+	requestsTotal.Inc(model)
+	requestDuration.Observe(latency.Seconds(), model)
+	if err != nil {
+		requestErrors.Inc(model, errorReason(err))
+	}
+}
+
+// errorReason classifies a request error for metrics
+func errorReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,304 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Default OpenAI-compatible endpoints
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOllamaBaseURL = "http://localhost:11434/v1"
+)
+
+// OpenAIClient implements game.LLMClient for OpenAI-compatible chat
+// completions APIs, including local servers like Ollama and llama.cpp
+type OpenAIClient struct {
+	apiKey     string
+	model      string
+	sampling   sampling
+	prices     map[string]config.ModelPrice // For cost estimates
+	httpClient *http.Client
+	baseURL    string
+	tools      bool // Declare actions as a function tool; some local servers lack tool support
+}
+
+// NewOpenAIClient creates a chat completions client. The API key may be
+// empty for local servers.
+func NewOpenAIClient(baseURL, apiKey, model string, timeout time.Duration) *OpenAIClient {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	return &OpenAIClient{
+		apiKey:   apiKey,
+		model:    model,
+		sampling: defaultSampling(),
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		tools:   true,
+	}
+}
+
+// OpenAIRequest represents a chat completions request
+type OpenAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []OpenAIMessage       `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	TopP           *float64              `json:"top_p,omitempty"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+	Tools          []OpenAITool          `json:"tools,omitempty"`
+	ToolChoice     *OpenAIToolChoice     `json:"tool_choice,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *OpenAIStreamOptions  `json:"stream_options,omitempty"`
+}
+
+// OpenAIStreamOptions asks for token usage in the final streamed chunk
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// OpenAIMessage is one chat message
+type OpenAIMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []OpenAIToolCall `json:"tool_calls,omitempty"`
+}
+
+// OpenAITool declares a function the model can call
+type OpenAITool struct {
+	Type     string         `json:"type"` // "function"
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction describes a callable function and its JSON Schema parameters
+type OpenAIFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// OpenAIToolChoice forces the model to call a specific function
+type OpenAIToolChoice struct {
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+// OpenAIToolCall is a function call returned by the model
+type OpenAIToolCall struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded
+	} `json:"function"`
+}
+
+// OpenAIResponseFormat requests structured output
+type OpenAIResponseFormat struct {
+	Type string `json:"type"` // "json_object"
+}
+
+// openAIUsage is the token usage of a chat completion
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// openAIError is an error returned in a response body
+type openAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// OpenAIResponse represents a chat completions response
+type OpenAIResponse struct {
+	Choices []struct {
+		Message OpenAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+// OpenAIStreamChunk is one event of a streamed chat completion. Tool call
+// arguments arrive in pieces keyed by the call's index.
+type OpenAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int `json:"index"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+// maxStreamToolCalls bounds the tool call index accepted from a stream
+const maxStreamToolCalls = 8
+
+// GetAction sends a prompt to the chat completions API and returns the parsed action
+func (c *OpenAIClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	start := time.Now()
+	model, params := requestParams(ctx, c.model, c.sampling)
+	action, err := c.getAction(ctx, agentID, prompt, model, params)
+	observeRequest(model, time.Since(start), err)
+	return action, err
+}
+
+func (c *OpenAIClient) getAction(ctx context.Context, agentID uuid.UUID, prompt, model string, params sampling) (game.Action, error) {
+	logger := slog.With("agent_id", agentID, "model", model)
+	req := OpenAIRequest{
+		Model:       model,
+		Messages:    []OpenAIMessage{{Role: "user", Content: prompt}},
+		Temperature: params.temperature,
+		TopP:        params.topP,
+		MaxTokens:   params.maxTokens,
+	}
+	if schema, ok := game.ActionSchemaFromContext(ctx); ok && c.tools {
+		req.Tools = []OpenAITool{{
+			Type:     "function",
+			Function: OpenAIFunction{Name: ActionToolName, Description: actionToolDescription, Parameters: schema},
+		}}
+		req.ToolChoice = &OpenAIToolChoice{Type: "function"}
+		req.ToolChoice.Function.Name = ActionToolName
+	} else {
+		req.ResponseFormat = &OpenAIResponseFormat{Type: "json_object"}
+	}
+
+	var message OpenAIMessage
+	var err error
+	if stream, ok := game.ThinkingStreamFromContext(ctx); ok {
+		message, err = c.completeStream(ctx, logger, req, newReasoningStream(stream).write)
+	} else {
+		message, err = c.complete(ctx, logger, req)
+	}
+	if err != nil {
+		return game.WaitAction(agentID), err
+	}
+	for _, call := range message.ToolCalls {
+		if call.Function.Name == ActionToolName {
+			return parseToolArguments(agentID, call.Function.Arguments)
+		}
+	}
+	return ParseActionFromText(agentID, message.Content) // Model answered in text anyway
+}
+
+// GenerateText sends a free-form prompt and returns the reply text
+func (c *OpenAIClient) GenerateText(ctx context.Context, prompt string) (string, error) {
+	start := time.Now()
+	message, err := c.complete(ctx, slog.With("model", c.model), OpenAIRequest{
+		Model:       c.model,
+		Messages:    []OpenAIMessage{{Role: "user", Content: prompt}},
+		Temperature: 0.9,
+		MaxTokens:   512,
+	})
+	observeRequest(c.model, time.Since(start), err)
+	return message.Content, err
+}
+
+// complete sends one chat completions request and returns the first choice's message
+func (c *OpenAIClient) complete(ctx context.Context, logger *slog.Logger, reqBody OpenAIRequest) (OpenAIMessage, error) {
+	body, err := postJSON(ctx, c.httpClient, logger, c.baseURL+"/chat/completions", c.headers(), reqBody)
+	if err != nil {
+		return OpenAIMessage{}, err
+	}
+
+	var openaiResp OpenAIResponse
+	if err := json.Unmarshal(body, &openaiResp); err != nil {
+		logger.Error("LLM response parse failed", "error", err)
+		return OpenAIMessage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if openaiResp.Error != nil {
+		logger.Error("LLM API returned error", "error_type", openaiResp.Error.Type, "error_message", openaiResp.Error.Message)
+		return OpenAIMessage{}, fmt.Errorf("API error: %s", openaiResp.Error.Message)
+	}
+
+	if usage := openaiResp.Usage; usage != nil {
+		reportUsage(ctx, c.prices, reqBody.Model, usage.PromptTokens, usage.CompletionTokens)
+	}
+
+	if len(openaiResp.Choices) == 0 {
+		return OpenAIMessage{}, fmt.Errorf("empty response from API")
+	}
+
+	return openaiResp.Choices[0].Message, nil
+}
+
+// completeStream streams one chat completions request, passing content and
+// tool call argument deltas to onText, and returns the assembled message
+func (c *OpenAIClient) completeStream(ctx context.Context, logger *slog.Logger, reqBody OpenAIRequest, onText func(string)) (OpenAIMessage, error) {
+	reqBody.Stream = true
+	reqBody.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+
+	var message OpenAIMessage
+	var content strings.Builder
+	err := postStream(ctx, c.httpClient, logger, c.baseURL+"/chat/completions", c.headers(), reqBody, func(_ string, data []byte) error {
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			logger.Error("LLM stream parse failed", "error", err)
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			logger.Error("LLM API returned error", "error_type", chunk.Error.Type, "error_message", chunk.Error.Message)
+			return fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if usage := chunk.Usage; usage != nil {
+			reportUsage(ctx, c.prices, reqBody.Model, usage.PromptTokens, usage.CompletionTokens)
+		}
+
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			onText(choice.Delta.Content)
+			for _, call := range choice.Delta.ToolCalls {
+				if call.Index < 0 || call.Index >= maxStreamToolCalls {
+					continue
+				}
+				for len(message.ToolCalls) <= call.Index {
+					message.ToolCalls = append(message.ToolCalls, OpenAIToolCall{Type: "function"})
+				}
+				fn := &message.ToolCalls[call.Index].Function
+				fn.Name += call.Function.Name
+				fn.Arguments += call.Function.Arguments
+				onText(call.Function.Arguments)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return OpenAIMessage{}, err
+	}
+
+	message.Role = "assistant"
+	message.Content = content.String()
+	if message.Content == "" && len(message.ToolCalls) == 0 {
+		return OpenAIMessage{}, fmt.Errorf("empty response from API")
+	}
+	return message, nil
+}
+
+// headers returns the auth headers, if an API key is configured
+func (c *OpenAIClient) headers() map[string]string {
+	if c.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + c.apiKey}
+}
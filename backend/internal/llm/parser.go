@@ -2,38 +2,315 @@ package llm
 
 import (
 	"encoding/json"
-	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lucas/promptlands/internal/game"
 )
 
+// Repairs applied to malformed response text before parsing, recorded in
+// game.Action.Repairs alongside those from game.ParseAction
+const (
+	RepairExtracted     = "extracted_object" // JSON object picked out of surrounding text or code fences
+	RepairComments      = "comments"         // Removed // and /* */ comments
+	RepairSingleQuotes  = "single_quotes"    // Single-quoted strings
+	RepairUnquotedKeys  = "unquoted_keys"    // Object keys without quotes
+	RepairTrailingComma = "trailing_comma"   // Comma before a closing brace or bracket
+	RepairTruncated     = "truncated"        // Closed an unterminated string, object or array
+)
+
 // ExtractJSON attempts to extract JSON from LLM response text
 // LLMs sometimes wrap JSON in markdown code blocks or add extra text
 func ExtractJSON(text string) string {
+	extracted, _ := extractJSON(text)
+	return extracted
+}
+
+// ParseActionFromText parses an action from potentially messy LLM output
+func ParseActionFromText(agentID uuid.UUID, text string) (game.Action, error) {
+	jsonStr, repairs := extractJSON(text)
+	action, err := game.ParseAction(agentID, []byte(jsonStr))
+	action.Raw = text
+	if err == nil {
+		action.Repairs = append(repairs, action.Repairs...)
+	}
+	return action, err
+}
+
+// extractJSON picks the action object out of text and repairs common
+// defects. With several objects, the last one with an "action" wins, since
+// models tend to echo the format or think out loud before answering.
+func extractJSON(text string) (string, []string) {
 	text = strings.TrimSpace(text)
+	objects := jsonObjects(text)
+	if len(objects) == 0 {
+		return text, nil // Let the JSON parser report what's wrong
+	}
 
-	// Try to find JSON in markdown code blocks
-	codeBlockPattern := regexp.MustCompile("```(?:json)?\\s*([\\s\\S]*?)```")
-	if matches := codeBlockPattern.FindStringSubmatch(text); len(matches) > 1 {
-		return strings.TrimSpace(matches[1])
+	var repairs []string
+	if len(objects) > 1 || objects[0] != text {
+		repairs = append(repairs, RepairExtracted)
 	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		repaired, fixes := repairJSON(objects[i])
+		if hasAction(repaired) {
+			return repaired, append(repairs, fixes...)
+		}
+	}
+	repaired, fixes := repairJSON(objects[0])
+	return repaired, append(repairs, fixes...)
+}
 
-	// Try to find raw JSON object
-	jsonPattern := regexp.MustCompile(`\{[^{}]*\}`)
-	if match := jsonPattern.FindString(text); match != "" {
-		return match
+// hasAction reports whether s decodes to an object with an action
+func hasAction(s string) bool {
+	var obj struct {
+		Action string `json:"action"`
 	}
+	return json.Unmarshal([]byte(s), &obj) == nil && obj.Action != ""
+}
 
-	// Return original if no patterns match
-	return text
+// jsonObjects returns the top-level {...} spans in text, skipping braces
+// inside strings. An object still open at the end of text is included, so
+// truncated responses can be repaired.
+func jsonObjects(text string) []string {
+	var objects []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case depth > 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+			if depth == 0 {
+				objects = append(objects, text[start:i+1])
+			}
+		}
+	}
+	if depth > 0 {
+		objects = append(objects, text[start:])
+	}
+	return objects
 }
 
-// ParseActionFromText parses an action from potentially messy LLM output
-func ParseActionFromText(agentID uuid.UUID, text string) (game.Action, error) {
-	jsonStr := ExtractJSON(text)
-	return game.ParseAction(agentID, []byte(jsonStr))
+// repairJSON fixes common defects in an almost-JSON object and lists the
+// repairs that changed it. Valid JSON is returned unchanged.
+func repairJSON(s string) (string, []string) {
+	if json.Valid([]byte(s)) {
+		return s, nil
+	}
+
+	var repairs []string
+	for _, pass := range []struct {
+		name string
+		fix  func(string) string
+	}{
+		{RepairComments, stripComments},
+		{RepairSingleQuotes, doubleQuoteStrings},
+		{RepairUnquotedKeys, quoteKeys},
+		{RepairTruncated, closeTruncated},
+		{RepairTrailingComma, dropTrailingCommas},
+	} {
+		if fixed := pass.fix(s); fixed != s {
+			s = fixed
+			repairs = append(repairs, pass.name)
+		}
+	}
+	return s, repairs
+}
+
+// scanJSON walks s calling outside for each byte that isn't inside a
+// string; bytes inside strings are copied to the output unchanged.
+// outside returns how many bytes it consumed (at least 1).
+func scanJSON(s string, outside func(out *strings.Builder, i int) int) string {
+	var out strings.Builder
+	var quote byte
+	for i := 0; i < len(s); {
+		c := s[i]
+		if quote != 0 {
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				out.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			i++
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		i += outside(&out, i)
+	}
+	return out.String()
+}
+
+// stripComments removes // line and /* block */ comments outside strings
+func stripComments(s string) string {
+	return scanJSON(s, func(out *strings.Builder, i int) int {
+		switch {
+		case strings.HasPrefix(s[i:], "//"):
+			if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+				return end
+			}
+			return len(s) - i
+		case strings.HasPrefix(s[i:], "/*"):
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				return end + 4
+			}
+			return len(s) - i
+		}
+		out.WriteByte(s[i])
+		return 1
+	})
+}
+
+// doubleQuoteStrings rewrites 'single-quoted' strings as "double-quoted"
+func doubleQuoteStrings(s string) string {
+	var out strings.Builder
+	inDouble := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inDouble:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				out.WriteByte(s[i])
+			} else if c == '"' {
+				inDouble = false
+			}
+		case c == '"':
+			inDouble = true
+			out.WriteByte(c)
+		case c == '\'':
+			out.WriteByte('"')
+			for i++; i < len(s) && s[i] != '\''; i++ {
+				switch {
+				case s[i] == '\\' && i+1 < len(s) && s[i+1] == '\'':
+					out.WriteByte('\'') // \' needs no escape in double quotes
+					i++
+				case s[i] == '\\' && i+1 < len(s):
+					out.WriteString(s[i : i+2])
+					i++
+				case s[i] == '"':
+					out.WriteString(`\"`)
+				default:
+					out.WriteByte(s[i])
+				}
+			}
+			if i < len(s) {
+				out.WriteByte('"')
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// quoteKeys quotes bare identifiers used as object keys
+func quoteKeys(s string) string {
+	return scanJSON(s, func(out *strings.Builder, i int) int {
+		c := s[i]
+		if !isIdentStart(c) || !afterKeyDelimiter(s[:i]) {
+			out.WriteByte(c)
+			return 1
+		}
+		end := i + 1
+		for end < len(s) && (isIdentStart(s[end]) || (s[end] >= '0' && s[end] <= '9')) {
+			end++
+		}
+		if rest := strings.TrimLeft(s[end:], " \t\r\n"); !strings.HasPrefix(rest, ":") {
+			out.WriteString(s[i:end]) // A bare value like true or null
+			return end - i
+		}
+		out.WriteString(`"` + s[i:end] + `"`)
+		return end - i
+	})
+}
+
+// isIdentStart reports whether c can start a bare key
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// afterKeyDelimiter reports whether the last non-space byte of s opens an
+// object or separates members, so a key may follow
+func afterKeyDelimiter(s string) bool {
+	s = strings.TrimRight(s, " \t\r\n")
+	return strings.HasSuffix(s, "{") || strings.HasSuffix(s, ",")
+}
+
+// closeTruncated closes an unterminated string and any open objects and
+// arrays at the end of s, dropping a dangling comma or colon
+func closeTruncated(s string) string {
+	var stack []byte
+	inString := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			stack = append(stack, '}')
+		case c == '[':
+			stack = append(stack, ']')
+		case (c == '}' || c == ']') && len(stack) > 0:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if !inString && len(stack) == 0 {
+		return s
+	}
+
+	if inString {
+		s = strings.TrimSuffix(s, "\\") + `"`
+	}
+	s = strings.TrimRight(s, " \t\r\n")
+	s = strings.TrimRight(s, ",:")
+	for i := len(stack) - 1; i >= 0; i-- {
+		s += string(stack[i])
+	}
+	return s
+}
+
+// dropTrailingCommas removes commas directly before } or ]
+func dropTrailingCommas(s string) string {
+	return scanJSON(s, func(out *strings.Builder, i int) int {
+		if s[i] == ',' {
+			rest := strings.TrimLeft(s[i+1:], " \t\r\n")
+			if strings.HasPrefix(rest, "}") || strings.HasPrefix(rest, "]") {
+				return 1
+			}
+		}
+		out.WriteByte(s[i])
+		return 1
+	})
 }
 
 // ValidateAction checks if an action is valid for the current game state
@@ -87,61 +364,3 @@ func isValidDirection(dir game.Direction) bool {
 		return false
 	}
 }
-
-// ActionStats tracks LLM response statistics
-type ActionStats struct {
-	TotalRequests   int
-	SuccessfulParse int
-	ParseErrors     int
-	Timeouts        int
-	ValidationFails int
-}
-
-// NewActionStats creates a new stats tracker
-func NewActionStats() *ActionStats {
-	return &ActionStats{}
-}
-
-// RecordSuccess records a successful parse
-func (s *ActionStats) RecordSuccess() {
-	s.TotalRequests++
-	s.SuccessfulParse++
-}
-
-// RecordParseError records a parse error
-func (s *ActionStats) RecordParseError() {
-	s.TotalRequests++
-	s.ParseErrors++
-}
-
-// RecordTimeout records a timeout
-func (s *ActionStats) RecordTimeout() {
-	s.TotalRequests++
-	s.Timeouts++
-}
-
-// RecordValidationFail records a validation failure
-func (s *ActionStats) RecordValidationFail() {
-	s.TotalRequests++
-	s.ValidationFails++
-}
-
-// SuccessRate returns the success rate as a percentage
-func (s *ActionStats) SuccessRate() float64 {
-	if s.TotalRequests == 0 {
-		return 0
-	}
-	return float64(s.SuccessfulParse) / float64(s.TotalRequests) * 100
-}
-
-// MarshalJSON implements json.Marshaler
-func (s *ActionStats) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
-		"total_requests":    s.TotalRequests,
-		"successful_parse":  s.SuccessfulParse,
-		"parse_errors":      s.ParseErrors,
-		"timeouts":          s.Timeouts,
-		"validation_fails":  s.ValidationFails,
-		"success_rate":      s.SuccessRate(),
-	})
-}
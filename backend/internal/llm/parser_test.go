@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+func TestParseActionFromText_Repairs(t *testing.T) {
+	agentID := uuid.New()
+	cases := []struct {
+		name    string
+		text    string
+		want    game.ActionType
+		dir     game.Direction
+		repairs []string
+	}{
+		{name: "clean", text: `{"action": "CLAIM"}`, want: game.ActionClaim},
+		{name: "code fence", text: "```json\n{\"action\": \"CLAIM\"}\n```", want: game.ActionClaim,
+			repairs: []string{RepairExtracted}},
+		{name: "nested braces", text: `I'll move. {"action": "MOVE", "params": {"direction": "north"}} done`, want: game.ActionMove, dir: game.DirNorth,
+			repairs: []string{RepairExtracted, game.RepairNestedParams}},
+		{name: "braces in strings", text: `{"action": "MESSAGE", "message": "meet at {3,4}"}`, want: game.ActionMessage},
+		{name: "trailing comma", text: `{"action": "MOVE", "direction": "south",}`, want: game.ActionMove, dir: game.DirSouth,
+			repairs: []string{RepairTrailingComma}},
+		{name: "single quotes", text: `{'action': 'MOVE', 'direction': 'east'}`, want: game.ActionMove, dir: game.DirEast,
+			repairs: []string{RepairSingleQuotes}},
+		{name: "unquoted keys", text: `{action: "MOVE", direction: "W", steps: 2}`, want: game.ActionMove, dir: game.DirWest,
+			repairs: []string{RepairUnquotedKeys, game.RepairDirection}},
+		{name: "comments", text: "{\"action\": \"CLAIM\" // grab land\n}", want: game.ActionClaim,
+			repairs: []string{RepairComments}},
+		{name: "multiple objects", text: `Format: {"action": "..."} Example {"foo": 1} Answer: {"action": "harvest"}`, want: game.ActionHarvest,
+			repairs: []string{RepairExtracted, game.RepairActionCase}},
+		{name: "truncated", text: `{"action": "MOVE", "direction": "up", "reasoning": "head for the fore`, want: game.ActionMove, dir: game.DirNorth,
+			repairs: []string{RepairTruncated, game.RepairDirection}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			action, err := ParseActionFromText(agentID, c.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if action.Type != c.want || action.Params.Direction != c.dir {
+				t.Errorf("got %s %q, want %s %q", action.Type, action.Params.Direction, c.want, c.dir)
+			}
+			if !reflect.DeepEqual(action.Repairs, c.repairs) {
+				t.Errorf("expected repairs %v, got %v", c.repairs, action.Repairs)
+			}
+			if action.Raw != c.text {
+				t.Errorf("expected raw response to be kept, got %q", action.Raw)
+			}
+		})
+	}
+}
+
+func FuzzParseActionFromText(f *testing.F) {
+	for _, seed := range []string{
+		`{"action": "MOVE", "direction": "north"}`,
+		"```json\n{'action': 'move', direction: 'N',}\n```",
+		`text {"a": {"b": "}"}} {"action": "CLAIM" /* x */`,
+		`{"action": "MESSAGE", "message": "it\'s \"fine\""`,
+		`{{{{`,
+		`}`,
+	} {
+		f.Add(seed)
+	}
+	agentID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, text string) {
+		action, err := ParseActionFromText(agentID, text)
+		if action.AgentID != agentID || action.Raw != text {
+			t.Fatalf("unexpected action %+v", action)
+		}
+		if err != nil && action.Type != game.ActionWait {
+			t.Fatalf("expected WAIT on error, got %s", action.Type)
+		}
+		if err == nil && action.Type == game.ActionMove {
+			switch action.Params.Direction {
+			case game.DirNorth, game.DirSouth, game.DirEast, game.DirWest:
+			default:
+				t.Fatalf("MOVE with invalid direction %q", action.Params.Direction)
+			}
+		}
+	})
+}
@@ -75,6 +75,12 @@ func buildPrompt(ctx game.AgentContext) string {
 	sb.WriteString(fmt.Sprintf("Valid moves: %s\n", strings.Join(validMoves, ", ")))
 	sb.WriteString("\n")
 
+	// Outcome of the previous action, so the agent can correct mistakes
+	if result := ctx.LastResult; result != nil {
+		sb.WriteString(formatLastResult(*result, ctx.Reask))
+		sb.WriteString("\n")
+	}
+
 	// Inventory
 	if ctx.Agent.Inventory != nil {
 		sb.WriteString("[Inventory]\n")
@@ -209,6 +215,24 @@ func buildPrompt(ctx game.AgentContext) string {
 	return sb.String()
 }
 
+// formatLastResult describes the agent's previous action result. A re-ask
+// reports this tick's rejected action instead.
+func formatLastResult(result game.ActionResult, reask bool) string {
+	if reask {
+		return fmt.Sprintf("[Action Rejected]\nYour %s action can't be done: %s. Choose a different action.\n", result.Action, result.Message)
+	}
+
+	status := "succeeded"
+	if !result.Success {
+		status = "FAILED"
+	}
+	line := fmt.Sprintf("[Last Action]\n%s %s", result.Action, status)
+	if result.Message != "" {
+		line += ": " + result.Message
+	}
+	return line + "\n"
+}
+
 // formatWorldObject formats a world object for the prompt
 func formatWorldObject(obj *game.WorldObject, agentPos game.Position) string {
 	pos := obj.Position
@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// ClientRegistry routes each agent's requests to a client for the provider
+// in its game.ModelSettings, so agents on different models can share a
+// match. The configured provider is the default; clients for other
+// providers are created on first use with keys from the environment.
+type ClientRegistry struct {
+	base config.LLMConfig
+
+	mu      sync.Mutex
+	clients map[string]game.LLMClient
+}
+
+// NewClientRegistry creates a registry whose default client is built from cfg
+func NewClientRegistry(cfg config.LLMConfig) (*ClientRegistry, error) {
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderGemini
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientRegistry{
+		base:    cfg,
+		clients: map[string]game.LLMClient{cfg.Provider: client},
+	}, nil
+}
+
+// Register sets the client used for a provider, replacing any existing one
+func (r *ClientRegistry) Register(provider string, client game.LLMClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[provider] = client
+}
+
+// client returns the client for a provider ("" = default), creating it if needed
+func (r *ClientRegistry) client(provider string) (game.LLMClient, error) {
+	if provider == "" {
+		provider = r.base.Provider
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client, ok := r.clients[provider]; ok {
+		return client, nil
+	}
+
+	cfg := r.base
+	cfg.Provider = provider
+	cfg.BaseURL = "" // The configured endpoint belongs to the default provider
+	cfg.APIKey = config.APIKeyFromEnv(provider)
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[provider] = client
+	return client, nil
+}
+
+// GetAction sends the prompt to the client for the agent's provider
+func (r *ClientRegistry) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	settings, _ := game.ModelSettingsFromContext(ctx)
+	client, err := r.client(settings.Provider)
+	if err != nil {
+		return game.WaitAction(agentID), err
+	}
+	return client.GetAction(ctx, agentID, prompt)
+}
+
+// GenerateText uses the default provider's client
+func (r *ClientRegistry) GenerateText(ctx context.Context, prompt string) (string, error) {
+	client, err := r.client("")
+	if err != nil {
+		return "", err
+	}
+	gen, ok := client.(game.TextGenerator)
+	if !ok {
+		return "", fmt.Errorf("provider %s can't generate text", r.base.Provider)
+	}
+	return gen.GenerateText(ctx, prompt)
+}
+
+// ValidateModel rejects unknown providers and models agents may not pick.
+// Besides the configured model, only models listed for the provider in
+// llm.models are allowed, since requests are billed to the server. Clients
+// that validate their own models, like scripted bots, decide for themselves.
+func (r *ClientRegistry) ValidateModel(settings game.ModelSettings) error {
+	provider := settings.Provider
+	if provider == "" {
+		provider = r.base.Provider
+	}
+	if provider == r.base.Provider && (settings.Model == "" || settings.Model == r.base.Model) {
+		return nil
+	}
+
+	r.mu.Lock()
+	client, registered := r.clients[provider]
+	r.mu.Unlock()
+	if validator, ok := client.(game.ModelValidator); registered && ok {
+		return validator.ValidateModel(settings)
+	}
+
+	if settings.Model == "" {
+		return fmt.Errorf("model is required when using provider %s", provider)
+	}
+	if !slices.Contains(r.base.Models[provider], settings.Model) {
+		return fmt.Errorf("model %s is not available for provider %s", settings.Model, provider)
+	}
+	_, err := r.client(provider) // Unknown providers fail here
+	return err
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+func TestClientRegistry_RoutesByProvider(t *testing.T) {
+	var gotModel string
+	var gotTemperature, gotMaxTokens float64
+	srv := newTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"{\"action\":\"CLAIM\"}"}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			gotModel, _ = body["model"].(string)
+			gotTemperature, _ = body["temperature"].(float64)
+			gotMaxTokens, _ = body["max_tokens"].(float64)
+		})
+
+	registry, err := NewClientRegistry(config.LLMConfig{Provider: ProviderAnthropic, Model: "claude-test", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry.Register(ProviderOpenAI, NewOpenAIClient(srv.URL, "", "gpt-default", time.Second))
+
+	temperature := 0.2
+	ctx := game.WithModelSettings(context.Background(), game.ModelSettings{
+		Provider:    ProviderOpenAI,
+		Model:       "gpt-agent",
+		Temperature: &temperature,
+		MaxTokens:   64,
+	})
+	action, err := registry.GetAction(ctx, uuid.New(), "prompt")
+	if err != nil || action.Type != game.ActionClaim {
+		t.Fatalf("expected CLAIM, got %+v, %v", action, err)
+	}
+	if gotModel != "gpt-agent" || gotTemperature != 0.2 || gotMaxTokens != 64 {
+		t.Errorf("expected agent settings in request, got model %q temperature %v max_tokens %v", gotModel, gotTemperature, gotMaxTokens)
+	}
+}
+
+func TestClientRegistry_ValidateModel(t *testing.T) {
+	registry, err := NewClientRegistry(config.LLMConfig{
+		Model:  "gemini-test",
+		Models: map[string][]string{ProviderGemini: {"gemini-other"}, ProviderOllama: {"llama3"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	valid := map[string]game.ModelSettings{
+		"configured model":      {Model: "gemini-test"},
+		"listed default model":  {Model: "gemini-other"},
+		"listed provider model": {Provider: ProviderOllama, Model: "llama3"},
+	}
+	for name, settings := range valid {
+		if err := registry.ValidateModel(settings); err != nil {
+			t.Errorf("%s: expected valid, got %v", name, err)
+		}
+	}
+
+	invalid := map[string]game.ModelSettings{
+		"unlisted default model":  {Model: "gemini-ultra/../../v1"},
+		"unlisted provider model": {Provider: ProviderOllama, Model: "llama-405b"},
+		"provider without model":  {Provider: ProviderOllama},
+		"unlisted provider":       {Provider: ProviderOpenAI, Model: "gpt-4o"},
+		"unknown provider":        {Provider: "bogus", Model: "m"},
+	}
+	for name, settings := range invalid {
+		if err := registry.ValidateModel(settings); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Generation defaults for action requests when llm config leaves them unset
+const (
+	defaultTemperature = 0.7
+	defaultMaxTokens   = 256
+)
+
+// sampling holds the generation parameters of one request
+type sampling struct {
+	temperature float64
+	maxTokens   int
+	topP        *float64
+}
+
+// defaultSampling returns the built-in action request parameters
+func defaultSampling() sampling {
+	return sampling{temperature: defaultTemperature, maxTokens: defaultMaxTokens}
+}
+
+// configure applies the server's llm config on top of the defaults
+func (s *sampling) configure(cfg config.LLMConfig) {
+	if cfg.Temperature != nil {
+		s.temperature = *cfg.Temperature
+	}
+	if cfg.MaxTokens > 0 {
+		s.maxTokens = cfg.MaxTokens
+	}
+}
+
+// requestParams resolves the model and sampling for a request, applying
+// the agent's model settings attached to ctx
+func requestParams(ctx context.Context, model string, defaults sampling) (string, sampling) {
+	settings, ok := game.ModelSettingsFromContext(ctx)
+	if !ok {
+		return model, defaults
+	}
+	if settings.Model != "" {
+		model = settings.Model
+	}
+	if settings.Temperature != nil {
+		defaults.temperature = *settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		defaults.maxTokens = settings.MaxTokens
+	}
+	if settings.TopP != nil {
+		defaults.topP = settings.TopP
+	}
+	return model, defaults
+}
@@ -0,0 +1,365 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/metrics"
+)
+
+// Retry backoff bounds
+const (
+	retryBaseDelay = 200 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// errNoCapacity is returned when the rate limit can't admit a request
+// before its deadline
+var errNoCapacity = fmt.Errorf("%w: no capacity before the deadline", ErrRateLimited)
+
+var (
+	schedulerWait = metrics.Default.NewHistogram("promptlands_llm_scheduler_wait_seconds",
+		"Time LLM requests spent queued for a slot and rate limit token.", nil, "provider")
+	schedulerRetries = metrics.Default.NewCounter("promptlands_llm_retries_total",
+		"LLM requests retried after a 429 or server error.", "provider")
+)
+
+// Scheduler wraps an LLM client with limits shared by every game: each
+// provider gets a lane with a concurrency cap and a token bucket. Queued
+// requests are served round-robin across games, and 429s and server errors
+// are retried with jittered backoff while the request deadline allows.
+type Scheduler struct {
+	client          game.LLMClient
+	cfg             config.SchedulerConfig
+	defaultProvider string
+
+	mu    sync.Mutex
+	lanes map[string]*lane
+}
+
+// NewScheduler creates a scheduler in front of client. Requests without a
+// provider in their model settings use defaultProvider's lane.
+func NewScheduler(client game.LLMClient, cfg config.SchedulerConfig, defaultProvider string) *Scheduler {
+	if defaultProvider == "" {
+		defaultProvider = ProviderGemini
+	}
+	return &Scheduler{
+		client:          client,
+		cfg:             cfg,
+		defaultProvider: defaultProvider,
+		lanes:           make(map[string]*lane),
+	}
+}
+
+// GetAction schedules an action request on the agent's provider lane
+func (s *Scheduler) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	settings, _ := game.ModelSettingsFromContext(ctx)
+	var action game.Action
+	err := s.do(ctx, settings.Provider, func() error {
+		var err error
+		action, err = s.client.GetAction(ctx, agentID, prompt)
+		return err
+	})
+	if err != nil && action.Type == "" {
+		action = game.WaitAction(agentID)
+	}
+	return action, err
+}
+
+// GenerateText schedules a text request on the default provider's lane
+func (s *Scheduler) GenerateText(ctx context.Context, prompt string) (string, error) {
+	gen, ok := s.client.(game.TextGenerator)
+	if !ok {
+		return "", fmt.Errorf("LLM client can't generate text")
+	}
+	var text string
+	err := s.do(ctx, "", func() error {
+		var err error
+		text, err = gen.GenerateText(ctx, prompt)
+		return err
+	})
+	return text, err
+}
+
+// ValidateModel defers to the wrapped client
+func (s *Scheduler) ValidateModel(settings game.ModelSettings) error {
+	if validator, ok := s.client.(game.ModelValidator); ok {
+		return validator.ValidateModel(settings)
+	}
+	return nil
+}
+
+// do runs fn under the provider's limits, retrying transient failures
+func (s *Scheduler) do(ctx context.Context, provider string, fn func() error) error {
+	if provider == "" {
+		provider = s.defaultProvider
+	}
+	l := s.lane(provider)
+	gameID := game.GameIDFromContext(ctx)
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		if err := l.acquire(ctx, gameID); err != nil {
+			return err
+		}
+		err := l.wait(ctx)
+		schedulerWait.Observe(time.Since(start).Seconds(), provider)
+		if err == nil {
+			err = fn()
+		}
+		l.release()
+
+		if err == nil || attempt >= s.cfg.MaxRetries || !retryable(err) {
+			return err
+		}
+
+		delay := backoff(attempt)
+		var rateLimited *RateLimitError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			l.pause(rateLimited.RetryAfter)
+			delay = max(delay, rateLimited.RetryAfter)
+		}
+		if !fitsDeadline(ctx, delay) || sleepContext(ctx, delay) != nil {
+			return err
+		}
+		schedulerRetries.Inc(provider)
+	}
+}
+
+// lane returns the limits for a provider, creating them on first use
+func (s *Scheduler) lane(provider string) *lane {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lanes[provider]
+	if !ok {
+		cfg, ok := s.cfg.Providers[provider]
+		if !ok {
+			cfg = s.cfg.LaneConfig
+		}
+		l = newLane(cfg, time.Now())
+		s.lanes[provider] = l
+	}
+	return l
+}
+
+// retryable reports whether a request error is worth retrying
+func retryable(err error) bool {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, errNoCapacity):
+		return false
+	case errors.Is(err, ErrRateLimited):
+		return true
+	case errors.As(err, &apiErr):
+		return apiErr.Status >= 500
+	default:
+		return false
+	}
+}
+
+// backoff returns a jittered exponential delay for a retry attempt
+func backoff(attempt int) time.Duration {
+	d := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// fitsDeadline reports whether waiting d still leaves ctx time to act
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lane enforces one provider's concurrency cap and token bucket. Waiting
+// requests are queued per game and granted slots round-robin.
+type lane struct {
+	mu     sync.Mutex
+	limit  int // 0 = unlimited
+	active int
+	queues map[uuid.UUID][]chan struct{}
+	order  []uuid.UUID // Games with queued requests, next to serve first
+
+	rate        float64 // Tokens per second (0 = unlimited)
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // Set from Retry-After
+}
+
+// newLane creates a lane with a full token bucket
+func newLane(cfg config.LaneConfig, now time.Time) *lane {
+	burst := float64(max(cfg.Burst, 1))
+	return &lane{
+		limit:  cfg.MaxConcurrent,
+		queues: make(map[uuid.UUID][]chan struct{}),
+		rate:   cfg.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// acquire waits for a concurrency slot
+func (l *lane) acquire(ctx context.Context, gameID uuid.UUID) error {
+	l.mu.Lock()
+	if l.limit <= 0 || (l.active < l.limit && len(l.order) == 0) {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	if len(l.queues[gameID]) == 0 {
+		l.order = append(l.order, gameID)
+	}
+	l.queues[gameID] = append(l.queues[gameID], ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready: // Granted while giving up; hand the slot on
+			l.active--
+			l.dispatch()
+		default:
+			l.dequeue(gameID, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot for the next queued request
+func (l *lane) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.dispatch()
+}
+
+// dispatch grants free slots to queued requests, one game at a time.
+// Caller must hold l.mu.
+func (l *lane) dispatch() {
+	for (l.limit <= 0 || l.active < l.limit) && len(l.order) > 0 {
+		gameID := l.order[0]
+		l.order = l.order[1:]
+		queue := l.queues[gameID]
+		if len(queue) > 1 {
+			l.queues[gameID] = queue[1:]
+			l.order = append(l.order, gameID) // Back of the line behind other games
+		} else {
+			delete(l.queues, gameID)
+		}
+		l.active++
+		close(queue[0])
+	}
+}
+
+// dequeue removes a request that gave up waiting. Caller must hold l.mu.
+func (l *lane) dequeue(gameID uuid.UUID, ready chan struct{}) {
+	queue := l.queues[gameID]
+	for i, ch := range queue {
+		if ch == ready {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) > 0 {
+		l.queues[gameID] = queue
+		return
+	}
+	delete(l.queues, gameID)
+	for i, id := range l.order {
+		if id == gameID {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// queued returns the number of requests waiting for a slot
+func (l *lane) queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, queue := range l.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// wait takes a rate limit token, sleeping until one is available and any
+// Retry-After pause has passed
+func (l *lane) wait(ctx context.Context) error {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	if !fitsDeadline(ctx, delay) {
+		l.cancelReservation()
+		return errNoCapacity
+	}
+	return sleepContext(ctx, delay)
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (l *lane) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var delay time.Duration
+	if l.pausedUntil.After(now) {
+		delay = l.pausedUntil.Sub(now)
+	}
+	if l.rate > 0 {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			delay = max(delay, time.Duration(-l.tokens/l.rate*float64(time.Second)))
+		}
+	}
+	return delay
+}
+
+// cancelReservation returns a token taken by reserve
+func (l *lane) cancelReservation() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate > 0 {
+		l.tokens++
+	}
+}
+
+// pause holds new requests until d from now
+func (l *lane) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// fakeClient returns queued errors, then MOVE actions, recording each call
+type fakeClient struct {
+	mu      sync.Mutex
+	calls   []uuid.UUID
+	errs    []error
+	block   chan struct{} // When set, each call waits for a receive
+	entered chan struct{} // When set, signalled as each call starts
+}
+
+func (c *fakeClient) GetAction(ctx context.Context, agentID uuid.UUID, prompt string) (game.Action, error) {
+	if c.entered != nil {
+		c.entered <- struct{}{}
+	}
+	if c.block != nil {
+		<-c.block
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, agentID)
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return game.WaitAction(agentID), err
+	}
+	return game.Action{AgentID: agentID, Type: game.ActionMove}, nil
+}
+
+func (c *fakeClient) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func TestScheduler_SharesSlotsAcrossGames(t *testing.T) {
+	client := &fakeClient{block: make(chan struct{}), entered: make(chan struct{}, 4)}
+	s := NewScheduler(client, config.SchedulerConfig{LaneConfig: config.LaneConfig{MaxConcurrent: 1}}, "")
+	gameA, gameB := uuid.New(), uuid.New()
+	ctxA := game.WithGameID(context.Background(), gameA)
+	ctxB := game.WithGameID(context.Background(), gameB)
+
+	a := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	b := uuid.New()
+	var wg sync.WaitGroup
+	request := func(ctx context.Context, agentID uuid.UUID, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.GetAction(ctx, agentID, "prompt")
+		}()
+		// Wait for the request to take the slot or join the queue so the
+		// submission order is deterministic
+		for s.lane(ProviderGemini).queued() != queued {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	request(ctxA, a[0], 0)
+	<-client.entered // a[0] holds the slot
+	request(ctxA, a[1], 1)
+	request(ctxA, a[2], 2)
+	request(ctxB, b, 3)
+
+	for range 4 {
+		client.block <- struct{}{}
+	}
+	wg.Wait()
+
+	want := []uuid.UUID{a[0], a[1], b, a[2]}
+	for i, id := range want {
+		if client.calls[i] != id {
+			t.Fatalf("expected game B's request to be served between game A's, got call %d = %v", i, client.calls[i])
+		}
+	}
+}
+
+func TestScheduler_RetriesRateLimit(t *testing.T) {
+	client := &fakeClient{errs: []error{&RateLimitError{RetryAfter: 20 * time.Millisecond}}}
+	s := NewScheduler(client, config.SchedulerConfig{MaxRetries: 2}, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	action, err := s.GetAction(ctx, uuid.New(), "prompt")
+	if err != nil || action.Type != game.ActionMove {
+		t.Fatalf("expected MOVE after retry, got %+v, %v", action, err)
+	}
+	if client.callCount() != 2 {
+		t.Errorf("expected 2 calls, got %d", client.callCount())
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected retry to wait for Retry-After, took %v", elapsed)
+	}
+}
+
+func TestScheduler_GivesUpPastDeadline(t *testing.T) {
+	client := &fakeClient{errs: []error{&RateLimitError{RetryAfter: 5 * time.Second}}}
+	s := NewScheduler(client, config.SchedulerConfig{MaxRetries: 2}, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	action, err := s.GetAction(ctx, uuid.New(), "prompt")
+	if !errors.Is(err, ErrRateLimited) || action.Type != game.ActionWait {
+		t.Fatalf("expected rate limit error with WAIT, got %+v, %v", action, err)
+	}
+	if client.callCount() != 1 {
+		t.Errorf("expected no retry past the deadline, got %d calls", client.callCount())
+	}
+}
+
+func TestScheduler_DoesNotRetryClientErrors(t *testing.T) {
+	client := &fakeClient{errs: []error{&APIError{Status: 400}}}
+	s := NewScheduler(client, config.SchedulerConfig{MaxRetries: 2}, "")
+
+	if _, err := s.GetAction(context.Background(), uuid.New(), "prompt"); err == nil {
+		t.Fatal("expected error")
+	}
+	if client.callCount() != 1 {
+		t.Errorf("expected a 400 not to be retried, got %d calls", client.callCount())
+	}
+}
+
+func TestLane_TokenBucket(t *testing.T) {
+	now := time.Now()
+	l := newLane(config.LaneConfig{RequestsPerSecond: 10, Burst: 2}, now)
+
+	if d := l.reserve(now); d != 0 {
+		t.Errorf("expected first token immediately, got %v", d)
+	}
+	if d := l.reserve(now); d != 0 {
+		t.Errorf("expected burst token immediately, got %v", d)
+	}
+	if d := l.reserve(now); d != 100*time.Millisecond {
+		t.Errorf("expected 100ms wait once the burst is spent, got %v", d)
+	}
+	if d := l.reserve(now.Add(time.Second)); d != 0 {
+		t.Errorf("expected bucket to refill, got %v", d)
+	}
+}
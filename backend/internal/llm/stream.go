@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// postStream sends a JSON request and calls onEvent with the event type and
+// data of each server-sent event in the response until it ends or onEvent
+// returns an error. Error statuses are handled as in postJSON.
+func postStream(ctx context.Context, client *http.Client, logger *slog.Logger, url string, headers map[string]string, payload interface{}, onEvent func(event string, data []byte) error) error {
+	resp, err := sendJSON(ctx, client, logger, url, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var event string
+	var data bytes.Buffer
+	dispatch := func() error {
+		defer func() {
+			event = ""
+			data.Reset()
+		}()
+		if data.Len() == 0 || bytes.Equal(data.Bytes(), []byte("[DONE]")) {
+			return nil
+		}
+		return onEvent(event, data.Bytes())
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Error("LLM stream read failed", "error", err)
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	return dispatch()
+}
+
+// reasoningStream picks the "reasoning" string out of a streamed action
+// JSON object and passes each newly decoded piece to emit as it arrives
+type reasoningStream struct {
+	emit  func(string)
+	buf   []byte
+	pos   int  // Next byte to decode once the value has started
+	start bool // Inside the reasoning string value
+	done  bool
+}
+
+// newReasoningStream creates an extractor that reports to emit
+func newReasoningStream(emit func(string)) *reasoningStream {
+	return &reasoningStream{emit: emit}
+}
+
+// reasoningKey matches the start of the reasoning value
+const reasoningKey = `"reasoning"`
+
+// write adds a chunk of streamed response text
+func (r *reasoningStream) write(chunk string) {
+	if r.done || chunk == "" {
+		return
+	}
+	r.buf = append(r.buf, chunk...)
+
+	if !r.start {
+		i := bytes.Index(r.buf, []byte(reasoningKey))
+		if i < 0 {
+			return
+		}
+		// Skip whitespace and the colon up to the opening quote
+		j := i + len(reasoningKey)
+		for j < len(r.buf) && (r.buf[j] == ' ' || r.buf[j] == ':' || r.buf[j] == '\n' || r.buf[j] == '\t') {
+			j++
+		}
+		if j >= len(r.buf) {
+			return
+		}
+		if r.buf[j] != '"' {
+			r.done = true // Not a string; nothing to stream
+			return
+		}
+		r.start = true
+		r.pos = j + 1
+	}
+
+	var out strings.Builder
+	for r.pos < len(r.buf) {
+		c := r.buf[r.pos]
+		if c == '"' {
+			r.done = true
+			break
+		}
+		if c != '\\' {
+			if !utf8.FullRune(r.buf[r.pos:]) {
+				break // Wait for the rest of a split multi-byte character
+			}
+			_, size := utf8.DecodeRune(r.buf[r.pos:])
+			out.Write(r.buf[r.pos : r.pos+size])
+			r.pos += size
+			continue
+		}
+
+		if r.pos+1 >= len(r.buf) {
+			break // Escape split across chunks
+		}
+		switch esc := r.buf[r.pos+1]; esc {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r', 'b', 'f':
+			// Dropped from the live view
+		case 'u':
+			if r.pos+6 > len(r.buf) {
+				r.flush(&out) // Wait for the rest of the code point
+				return
+			}
+			if code, err := strconv.ParseUint(string(r.buf[r.pos+2:r.pos+6]), 16, 32); err == nil {
+				out.WriteRune(rune(code))
+			}
+			r.pos += 6
+			continue
+		default:
+			out.WriteByte(esc) // \" \\ \/
+		}
+		r.pos += 2
+	}
+	r.flush(&out)
+}
+
+// flush emits decoded text, if any
+func (r *reasoningStream) flush(out *strings.Builder) {
+	if out.Len() > 0 {
+		r.emit(out.String())
+	}
+}
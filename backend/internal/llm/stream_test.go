@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+func TestReasoningStream(t *testing.T) {
+	response := `{"action":"MOVE","reasoning":"Go \"north\",\nthen claim é – ok","direction":"north"}`
+
+	// Every split point must decode to the same text, including splits
+	// inside the key, escapes and multi-byte characters
+	for split := 1; split < len(response); split++ {
+		var got strings.Builder
+		r := newReasoningStream(func(s string) { got.WriteString(s) })
+		r.write(response[:split])
+		r.write(response[split:])
+		if want := "Go \"north\",\nthen claim é – ok"; got.String() != want {
+			t.Fatalf("split %d: expected %q, got %q", split, want, got.String())
+		}
+	}
+}
+
+func TestOpenAIClient_StreamsReasoning(t *testing.T) {
+	sse := "data: " + `{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"take_action","arguments":"{\"action\":\"CLAIM\",\"reas"}}]}}]}` + "\n\n" +
+		"data: " + `{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"oning\":\"Mine now\"}"}}]}}]}` + "\n\n" +
+		"data: " + `{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5}}` + "\n\n" +
+		"data: [DONE]\n\n"
+	srv := newTestServer(t, http.StatusOK, sse, func(r *http.Request, body map[string]interface{}) {
+		if body["stream"] != true {
+			t.Errorf("expected a streaming request, got %v", body["stream"])
+		}
+	})
+
+	client := NewOpenAIClient(srv.URL, "", "gpt-test", time.Second)
+	var thinking strings.Builder
+	var usage []game.TokenUsage
+	ctx := game.WithThinkingStream(context.Background(), func(delta string) { thinking.WriteString(delta) })
+	ctx = game.WithUsageReporter(ctx, func(u game.TokenUsage) { usage = append(usage, u) })
+
+	action, err := client.GetAction(ctx, uuid.New(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionClaim {
+		t.Errorf("expected CLAIM from the streamed tool call, got %+v", action)
+	}
+	if thinking.String() != "Mine now" {
+		t.Errorf("expected streamed reasoning %q, got %q", "Mine now", thinking.String())
+	}
+	if len(usage) != 1 || usage[0].CompletionTokens != 5 {
+		t.Errorf("expected usage from the final chunk, got %+v", usage)
+	}
+}
+
+func TestAnthropicClient_StreamsReasoning(t *testing.T) {
+	sse := "event: message_start\ndata: " + `{"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}` + "\n\n" +
+		"event: content_block_start\ndata: " + `{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","name":"take_action","input":{}}}` + "\n\n" +
+		"event: content_block_delta\ndata: " + `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"action\":\"WAIT\",\"reasoning\":\"Rest"}}` + "\n\n" +
+		"event: content_block_delta\ndata: " + `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"ing\"}"}}` + "\n\n" +
+		"event: message_delta\ndata: " + `{"type":"message_delta","usage":{"output_tokens":7}}` + "\n\n"
+	srv := newTestServer(t, http.StatusOK, sse, nil)
+
+	client := NewAnthropicClient(srv.URL, "key", "claude-test", time.Second)
+	var thinking strings.Builder
+	var usage []game.TokenUsage
+	ctx := game.WithThinkingStream(context.Background(), func(delta string) { thinking.WriteString(delta) })
+	ctx = game.WithUsageReporter(ctx, func(u game.TokenUsage) { usage = append(usage, u) })
+
+	action, err := client.GetAction(ctx, uuid.New(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionWait || action.Reasoning != "Resting" {
+		t.Errorf("expected WAIT with assembled reasoning, got %+v", action)
+	}
+	if thinking.String() != "Resting" {
+		t.Errorf("expected streamed reasoning %q, got %q", "Resting", thinking.String())
+	}
+	if len(usage) != 1 || usage[0].PromptTokens != 12 || usage[0].CompletionTokens != 7 {
+		t.Errorf("expected usage from message_start and message_delta, got %+v", usage)
+	}
+}
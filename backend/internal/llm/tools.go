@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+// Action tool declared to providers with native tool calling
+const (
+	ActionToolName        = "take_action"
+	actionToolDescription = "Take your action for this tick."
+)
+
+// parseToolArguments parses schema-constrained action JSON from a tool call
+// or structured response
+func parseToolArguments(agentID uuid.UUID, args string) (game.Action, error) {
+	action, err := game.ParseAction(agentID, []byte(args))
+	action.Raw = args
+	return action, err
+}
+
+// geminiSchema converts a JSON Schema to the OpenAPI subset Gemini accepts
+// as a responseSchema: upper-case types and no unsupported keywords
+func geminiSchema(schema map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "type":
+			if t, ok := value.(string); ok {
+				out[key] = strings.ToUpper(t)
+			}
+		case "properties":
+			props, _ := value.(map[string]interface{})
+			converted := make(map[string]interface{}, len(props))
+			for name, prop := range props {
+				if p, ok := prop.(map[string]interface{}); ok {
+					converted[name] = geminiSchema(p)
+				}
+			}
+			out[key] = converted
+		case "items":
+			if items, ok := value.(map[string]interface{}); ok {
+				out[key] = geminiSchema(items)
+			}
+		case "format", "additionalProperties", "$schema":
+			// Not supported by Gemini's schema subset
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
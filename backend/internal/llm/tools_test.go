@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucas/promptlands/internal/game"
+)
+
+func schemaContext() context.Context {
+	return game.WithActionSchema(context.Background(), game.ActionSchema([]game.ActionType{game.ActionFight, game.ActionMove}))
+}
+
+func TestOpenAIClient_ToolCall(t *testing.T) {
+	target := uuid.New()
+	srv := newTestServer(t, http.StatusOK,
+		`{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"type":"function","function":{"name":"take_action","arguments":"{\"action\":\"FIGHT\",\"target\":\"`+target.String()+`\"}"}}]}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			tools, _ := body["tools"].([]interface{})
+			if len(tools) != 1 || body["tool_choice"] == nil {
+				t.Errorf("expected a forced take_action tool, got tools %v choice %v", body["tools"], body["tool_choice"])
+			}
+			if body["response_format"] != nil {
+				t.Error("expected no JSON mode alongside tools")
+			}
+		})
+
+	client := NewOpenAIClient(srv.URL, "", "gpt-test", time.Second)
+	action, err := client.GetAction(schemaContext(), uuid.New(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionFight || action.Params.Target == nil || *action.Params.Target != target {
+		t.Errorf("expected FIGHT from tool call, got %+v", action)
+	}
+}
+
+func TestAnthropicClient_ToolUse(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK,
+		`{"content":[{"type":"text","text":"Heading out."},{"type":"tool_use","name":"take_action","input":{"action":"MOVE","direction":"east","steps":2}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			choice, _ := body["tool_choice"].(map[string]interface{})
+			if choice["name"] != ActionToolName {
+				t.Errorf("expected forced tool choice, got %v", body["tool_choice"])
+			}
+		})
+
+	client := NewAnthropicClient(srv.URL, "key", "claude-test", time.Second)
+	action, err := client.GetAction(schemaContext(), uuid.New(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Type != game.ActionMove || action.Params.Direction != game.DirEast || action.Params.Steps != 2 {
+		t.Errorf("expected MOVE east 2 from tool use, got %+v", action)
+	}
+}
+
+func TestGeminiClient_ResponseSchema(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK,
+		`{"candidates":[{"content":{"parts":[{"text":"{\"action\":\"MOVE\",\"direction\":\"south\"}"}]}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			genConfig, _ := body["generationConfig"].(map[string]interface{})
+			schema, _ := genConfig["responseSchema"].(map[string]interface{})
+			if schema["type"] != "OBJECT" {
+				t.Errorf("expected an OBJECT responseSchema, got %v", genConfig["responseSchema"])
+			}
+			props, _ := schema["properties"].(map[string]interface{})
+			if target, _ := props["target"].(map[string]interface{}); target["format"] != nil {
+				t.Errorf("expected unsupported format keyword to be dropped, got %v", target)
+			}
+		})
+
+	client := NewGeminiClient("key", "gemini-test", time.Second)
+	client.baseURL = srv.URL
+	action, err := client.GetAction(schemaContext(), uuid.New(), "prompt")
+	if err != nil || action.Type != game.ActionMove {
+		t.Errorf("expected MOVE, got %+v, %v", action, err)
+	}
+}
+
+func TestGeminiClient_EscapesModel(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"candidates":[{"content":{"parts":[{"text":"{\"action\":\"WAIT\"}"}]}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if r.URL.EscapedPath() != "/models/a%2F..%2Fb:generateContent" {
+				t.Errorf("expected escaped model in path, got %s", r.URL.EscapedPath())
+			}
+			if r.URL.Query().Get("key") != "key" {
+				t.Errorf("expected API key to stay in the query, got %s", r.URL.RawQuery)
+			}
+		})
+
+	client := NewGeminiClient("key", "a/../b", time.Second)
+	client.baseURL = srv.URL
+	client.GetAction(schemaContext(), uuid.New(), "prompt")
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lucas/promptlands/internal/config"
+	"github.com/lucas/promptlands/internal/game"
+	"github.com/lucas/promptlands/internal/metrics"
+)
+
+var tokensTotal = metrics.Default.NewCounter("promptlands_llm_tokens_total",
+	"Tokens used by LLM requests by kind (prompt, completion).", "model", "kind")
+
+// reportUsage records a response's token counts and passes them, with the
+// estimated cost, to the game that made the request
+func reportUsage(ctx context.Context, prices map[string]config.ModelPrice, model string, promptTokens, completionTokens int) {
+	tokensTotal.Add(float64(promptTokens), model, "prompt")
+	tokensTotal.Add(float64(completionTokens), model, "completion")
+
+	usage := game.TokenUsage{Model: model, PromptTokens: promptTokens, CompletionTokens: completionTokens}
+	if price, ok := priceFor(prices, model); ok {
+		usage.CostUSD = (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1e6
+	}
+	game.ReportUsage(ctx, usage)
+}
+
+// priceFor looks up a model's price by exact name, then by the longest
+// matching prefix, so "gpt-4o" also prices "gpt-4o-2024-08-06"
+func priceFor(prices map[string]config.ModelPrice, model string) (config.ModelPrice, bool) {
+	if price, ok := prices[model]; ok {
+		return price, true
+	}
+	var best string
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	price, ok := prices[best]
+	return price, ok && best != ""
+}
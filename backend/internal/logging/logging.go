@@ -0,0 +1,176 @@
+// Package logging configures the process-wide slog logger. Records below the
+// configured level are dropped unless they belong to a game that has debug
+// logging switched on at runtime.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Attribute keys shared by every package
+const (
+	KeyGameID  = "game_id"
+	KeyTick    = "tick"
+	KeyAgentID = "agent_id"
+)
+
+var (
+	level      = new(slog.LevelVar)
+	debugGames sync.Map // game ID string -> struct{}
+)
+
+// Setup installs the default logger. Format is "text" or "json"; level is
+// one of debug, info, warn or error.
+func Setup(w io.Writer, format, lvl string) error {
+	parsed, err := ParseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug} // Filtering happens in Handler
+	var next slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		next = slog.NewTextHandler(w, opts)
+	case "json":
+		next = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	slog.SetDefault(slog.New(&Handler{next: next}))
+	return nil
+}
+
+// ParseLevel parses a level name, defaulting to info when empty
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+// SetLevel changes the global log level
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// SetGameDebug turns debug logging for a single game on or off
+func SetGameDebug(gameID uuid.UUID, enabled bool) {
+	if enabled {
+		debugGames.Store(gameID.String(), struct{}{})
+	} else {
+		debugGames.Delete(gameID.String())
+	}
+}
+
+// GameDebug reports whether debug logging is on for a game
+func GameDebug(gameID uuid.UUID) bool {
+	_, ok := debugGames.Load(gameID.String())
+	return ok
+}
+
+// ForGame returns the default logger with the game_id attribute attached
+func ForGame(gameID uuid.UUID) *slog.Logger {
+	return slog.Default().With(KeyGameID, gameID)
+}
+
+// Handler applies the global level, letting through lower-level records of
+// games with debug logging enabled
+type Handler struct {
+	next   slog.Handler
+	gameID string // game_id attached via WithAttrs, if any
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, l slog.Level) bool {
+	if l >= level.Level() {
+		return true
+	}
+	if h.gameID != "" {
+		_, ok := debugGames.Load(h.gameID)
+		return ok
+	}
+	return anyGameDebug() // game_id may still be among the record's attrs
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < level.Level() {
+		gameID := h.gameID
+		if gameID == "" {
+			r.Attrs(func(a slog.Attr) bool {
+				if a.Key == KeyGameID {
+					gameID = attrString(a)
+					return false
+				}
+				return true
+			})
+		}
+		if _, ok := debugGames.Load(gameID); !ok {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := &Handler{next: h.next.WithAttrs(attrs), gameID: h.gameID}
+	for _, a := range attrs {
+		if a.Key == KeyGameID {
+			clone.gameID = attrString(a)
+		}
+	}
+	return clone
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), gameID: h.gameID}
+}
+
+// attrString renders an attribute value as a game ID key
+func attrString(a slog.Attr) string {
+	if id, ok := a.Value.Any().(uuid.UUID); ok {
+		return id.String()
+	}
+	return a.Value.String()
+}
+
+// anyGameDebug reports whether any game has debug logging enabled
+func anyGameDebug() bool {
+	found := false
+	debugGames.Range(func(any, any) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
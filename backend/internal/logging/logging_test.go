@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGameDebug(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Setup(&buf, "text", "info"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	quiet, loud := uuid.New(), uuid.New()
+	SetGameDebug(loud, true)
+	defer SetGameDebug(loud, false)
+
+	ForGame(quiet).Debug("quiet game detail")
+	ForGame(loud).Debug("loud game detail")
+	slog.Debug("loud game attr detail", KeyGameID, loud)
+	slog.Debug("global detail")
+	slog.Info("global info")
+
+	out := buf.String()
+	for _, want := range []string{"loud game detail", "loud game attr detail", "global info"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q to be logged, got:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"quiet game detail", "global detail"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected %q to be filtered, got:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestSetup_InvalidConfig(t *testing.T) {
+	if err := Setup(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("expected unknown format to fail")
+	}
+	if err := Setup(&bytes.Buffer{}, "text", "verbose"); err == nil {
+		t.Error("expected unknown level to fail")
+	}
+}
@@ -0,0 +1,341 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// counters, gauges and histograms in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets in seconds suited to request and tick
+// latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry served on /metrics
+var Default = NewRegistry()
+
+// metric is a named family that can write its samples
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families in registration order
+type Registry struct {
+	mu      sync.RWMutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// register adds a family, panicking on duplicate names like a misconfigured
+// metric definition should
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[m.name()] {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", m.name()))
+	}
+	r.names[m.name()] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{family: newFamily(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{family: newFamily(name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+// NewGaugeFunc registers an unlabeled gauge whose value is read at scrape time
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{family: newFamily(name, help, "gauge", nil), fn: fn})
+}
+
+// NewHistogram registers a histogram with the given buckets (nil for
+// DefaultBuckets) and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &Histogram{family: newFamily(name, help, "histogram", labels), buckets: buckets}
+	r.register(h)
+	return h
+}
+
+// WriteTo writes every metric in the Prometheus text format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.RUnlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// family holds the shared metadata and per-label-set series of a metric
+type family struct {
+	mu     sync.Mutex
+	fname  string
+	help   string
+	kind   string
+	labels []string
+	series map[string]*series
+}
+
+// series is one label combination of a family
+type series struct {
+	labelValues []string
+	value       float64  // Counter and gauge value
+	counts      []uint64 // Histogram: per-bucket (non-cumulative) counts
+	sum         float64  // Histogram: sum of observations
+	count       uint64   // Histogram: number of observations
+}
+
+func newFamily(name, help, kind string, labels []string) family {
+	return family{fname: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+}
+
+func (f *family) name() string {
+	return f.fname
+}
+
+// get returns the series for labelValues, creating it if needed.
+// Caller must hold f.mu.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.fname, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// sorted returns the family's series in label order. Caller must hold f.mu.
+func (f *family) sorted() []*series {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]*series, len(keys))
+	for i, key := range keys {
+		out[i] = f.series[key]
+	}
+	return out
+}
+
+// writeHeader writes the HELP and TYPE lines
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.fname, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.fname, f.kind)
+}
+
+// writeSample writes one sample line with optional extra label
+func (f *family) writeSample(w *bufio.Writer, suffix string, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(f.fname)
+	w.WriteString(suffix)
+	if len(labelValues) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, v := range labelValues {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", f.labels[i], escapeLabel(v))
+		}
+		if extraName != "" {
+			if len(labelValues) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// writeValues writes a counter or gauge family
+func (f *family) writeValues(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.writeHeader(w)
+	for _, s := range f.sorted() {
+		f.writeSample(w, "", s.labelValues, "", "", s.value)
+	}
+}
+
+// Counter is a monotonically increasing value per label set
+type Counter struct {
+	family
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the counter
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.get(labelValues).value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.writeValues(w)
+}
+
+// Gauge is a value per label set that can go up and down
+type Gauge struct {
+	family
+}
+
+// Set sets the gauge
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value = v
+	g.mu.Unlock()
+}
+
+// Add adds v (which may be negative) to the gauge
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value += v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.writeValues(w)
+}
+
+// gaugeFunc is a gauge computed on every scrape
+type gaugeFunc struct {
+	family
+	fn func() float64
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	g.writeSample(w, "", nil, "", "", g.fn())
+}
+
+// Histogram counts observations in cumulative buckets per label set
+type Histogram struct {
+	family
+	buckets []float64
+}
+
+// Observe records one observation
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(labelValues)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w)
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			h.writeSample(w, "_bucket", s.labelValues, "le", formatFloat(bound), float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", s.labelValues, "le", "+Inf", float64(s.count))
+		h.writeSample(w, "_sum", s.labelValues, "", "", s.sum)
+		h.writeSample(w, "_count", s.labelValues, "", "", float64(s.count))
+	}
+}
+
+// formatFloat formats a sample value the way Prometheus expects
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// countingWriter counts bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "Requests handled.", "model")
+	latency := r.NewHistogram("test_latency_seconds", "Request latency.", []float64{0.1, 1}, "model")
+	r.NewGaugeFunc("test_games", "Active games.", func() float64 { return 3 })
+
+	requests.Inc("b")
+	requests.Add(2, "a\"x")
+	latency.Observe(0.05, "a")
+	latency.Observe(0.5, "a")
+	latency.Observe(5, "a")
+
+	var out strings.Builder
+	if _, err := r.WriteTo(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP test_requests_total Requests handled.
+# TYPE test_requests_total counter
+test_requests_total{model="a\"x"} 2
+test_requests_total{model="b"} 1
+# HELP test_latency_seconds Request latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{model="a",le="0.1"} 1
+test_latency_seconds_bucket{model="a",le="1"} 2
+test_latency_seconds_bucket{model="a",le="+Inf"} 3
+test_latency_seconds_sum{model="a"} 5.55
+test_latency_seconds_count{model="a"} 3
+# HELP test_games Active games.
+# TYPE test_games gauge
+test_games 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("test_gauge", "A gauge.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate registration to panic")
+		}
+	}()
+	r.NewCounter("test_gauge", "Another metric.")
+}
@@ -2,12 +2,13 @@ package ws
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/lucas/promptlands/internal/logging"
 )
 
 const (
@@ -56,7 +57,7 @@ func NewHandler(hub *Hub, stateProvider GameStateProvider) *Handler {
 func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request, gameID uuid.UUID, playerAgentID *uuid.UUID) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
+		slog.Warn("WebSocket upgrade failed", logging.KeyGameID, gameID, "error", err)
 		return
 	}
 
@@ -103,7 +104,7 @@ func (c *Client) readPump() {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				c.logger().Warn("WebSocket error", "error", err)
 			}
 			break
 		}
@@ -161,7 +162,7 @@ func (c *Client) writePump() {
 func (c *Client) handleMessage(message []byte) {
 	var msg ClientMessage
 	if err := json.Unmarshal(message, &msg); err != nil {
-		log.Printf("Failed to parse client message: %v", err)
+		c.logger().Debug("Failed to parse client message", "error", err)
 		return
 	}
 
@@ -180,7 +181,7 @@ func (c *Client) handleMessage(message []byte) {
 		}
 
 	default:
-		log.Printf("Unknown message type: %s", msg.Type)
+		c.logger().Debug("Unknown client message type", "type", msg.Type)
 	}
 }
 
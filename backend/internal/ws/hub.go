@@ -2,11 +2,12 @@ package ws
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/lucas/promptlands/internal/logging"
 )
 
 // Client represents a WebSocket client connection
@@ -19,6 +20,21 @@ type Client struct {
 	hub           *Hub
 }
 
+// logger returns a logger carrying the client's game, agent and connection IDs
+func (c *Client) logger() *slog.Logger {
+	logger := logging.ForGame(c.GameID).With("client_id", c.ID)
+	if c.PlayerAgentID != nil {
+		logger = logger.With(logging.KeyAgentID, *c.PlayerAgentID)
+	}
+	return logger
+}
+
+// viewsAgent reports whether the client may see an agent's private messages:
+// spectators see every agent, players only their own
+func (c *Client) viewsAgent(agentID uuid.UUID) bool {
+	return c.PlayerAgentID == nil || *c.PlayerAgentID == agentID
+}
+
 // Hub manages all WebSocket connections
 type Hub struct {
 	mu                 sync.RWMutex
@@ -34,6 +50,7 @@ type Hub struct {
 type BroadcastMessage struct {
 	GameID  uuid.UUID
 	Message interface{}
+	AgentID *uuid.UUID // When set, only spectators and this agent's player receive the message
 }
 
 // PerPlayerBroadcastMessage contains a message to broadcast with per-player customization
@@ -69,6 +86,7 @@ func (h *Hub) Run() {
 		case msg := <-h.perPlayerBroadcast:
 			h.broadcastToGamePerPlayer(msg)
 		}
+		h.observeQueues()
 	}
 }
 
@@ -85,7 +103,7 @@ func (h *Hub) registerClient(client *Client) {
 			h.gameRooms[client.GameID] = make(map[*Client]bool)
 		}
 		h.gameRooms[client.GameID][client] = true
-		log.Printf("Client %s joined game %s", client.ID, client.GameID)
+		client.logger().Debug("Client joined game")
 	}
 }
 
@@ -105,7 +123,7 @@ func (h *Hub) unregisterClient(client *Client) {
 				delete(h.gameRooms, client.GameID)
 			}
 		}
-		log.Printf("Client %s disconnected", client.ID)
+		client.logger().Debug("Client disconnected")
 	}
 }
 
@@ -121,21 +139,25 @@ func (h *Hub) broadcastToGame(msg BroadcastMessage) {
 	// Make a copy of clients to avoid holding lock during send
 	clients := make([]*Client, 0, len(room))
 	for client := range room {
-		clients = append(clients, client)
+		if msg.AgentID == nil || client.viewsAgent(*msg.AgentID) {
+			clients = append(clients, client)
+		}
 	}
 	h.mu.RUnlock()
 
 	data, err := json.Marshal(msg.Message)
 	if err != nil {
-		log.Printf("Failed to marshal broadcast message: %v", err)
+		slog.Error("Failed to marshal broadcast message", logging.KeyGameID, msg.GameID, "error", err)
 		return
 	}
 
 	for _, client := range clients {
 		select {
 		case client.Send <- data:
+			broadcastBytes.Add(float64(len(data)))
 		default:
 			// Client buffer full, disconnect
+			slowClients.Inc()
 			h.unregister <- client
 		}
 	}
@@ -148,6 +170,19 @@ func (h *Hub) BroadcastToGame(gameID uuid.UUID, message interface{}) {
 		GameID:  gameID,
 		Message: message,
 	}
+	h.observeQueues()
+}
+
+// BroadcastToAgentViewers sends an agent's private message, such as its
+// reasoning, to the game's spectators and the agent's own player only
+// This implements the game.AgentViewers interface
+func (h *Hub) BroadcastToAgentViewers(gameID, agentID uuid.UUID, message interface{}) {
+	h.broadcast <- BroadcastMessage{
+		GameID:  gameID,
+		Message: message,
+		AgentID: &agentID,
+	}
+	h.observeQueues()
 }
 
 // TickUpdateMessage matches game.TickUpdate structure for JSON marshaling
@@ -179,6 +214,7 @@ func (h *Hub) BroadcastToGameWithVisibility(gameID uuid.UUID, baseUpdate interfa
 		VisibilityProvider: visibilityProvider,
 		InventoryProvider:  inventoryProvider,
 	}
+	h.observeQueues()
 }
 
 // broadcastToGamePerPlayer sends customized tick updates to each player
@@ -200,14 +236,14 @@ func (h *Hub) broadcastToGamePerPlayer(msg PerPlayerBroadcastMessage) {
 	// Marshal the base message once to extract components
 	baseData, err := json.Marshal(msg.BaseMessage)
 	if err != nil {
-		log.Printf("Failed to marshal base broadcast message: %v", err)
+		slog.Error("Failed to marshal base broadcast message", logging.KeyGameID, msg.GameID, "error", err)
 		return
 	}
 
 	// Parse the base message to extract components
 	var baseUpdate map[string]json.RawMessage
 	if err := json.Unmarshal(baseData, &baseUpdate); err != nil {
-		log.Printf("Failed to parse base broadcast message: %v", err)
+		slog.Error("Failed to parse base broadcast message", logging.KeyGameID, msg.GameID, "error", err)
 		return
 	}
 
@@ -217,7 +253,6 @@ func (h *Hub) broadcastToGamePerPlayer(msg PerPlayerBroadcastMessage) {
 		if client.PlayerAgentID != nil {
 			// Get visible tiles for this player
 			visibleTiles := msg.VisibilityProvider(*client.PlayerAgentID)
-			log.Printf("broadcastToGamePerPlayer: client %s has PlayerAgentID %s, visibleTiles=%d", client.ID, *client.PlayerAgentID, len(visibleTiles))
 
 			// Create customized message with visible tiles and inventory
 			customUpdate := make(map[string]interface{})
@@ -247,7 +282,7 @@ func (h *Hub) broadcastToGamePerPlayer(msg PerPlayerBroadcastMessage) {
 			}
 			data, err = json.Marshal(customUpdate)
 			if err != nil {
-				log.Printf("Failed to marshal customized message: %v", err)
+				client.logger().Error("Failed to marshal customized message", "error", err)
 				data = baseData // Fallback to base
 			}
 		} else {
@@ -257,8 +292,10 @@ func (h *Hub) broadcastToGamePerPlayer(msg PerPlayerBroadcastMessage) {
 
 		select {
 		case client.Send <- data:
+			broadcastBytes.Add(float64(len(data)))
 		default:
 			// Client buffer full, disconnect
+			slowClients.Inc()
 			h.unregister <- client
 		}
 	}
@@ -291,6 +328,20 @@ func (h *Hub) GetGameClientCount(gameID uuid.UUID) int {
 	return 0
 }
 
+// GetAgentViewerCount returns the number of clients that receive an agent's
+// private messages: the game's spectators and the agent's own player
+func (h *Hub) GetAgentViewerCount(gameID, agentID uuid.UUID) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	count := 0
+	for client := range h.gameRooms[gameID] {
+		if client.viewsAgent(agentID) {
+			count++
+		}
+	}
+	return count
+}
+
 // SendToClient sends a message to a specific client
 func (h *Hub) SendToClient(clientID uuid.UUID, message interface{}) {
 	h.mu.RLock()
@@ -298,7 +349,7 @@ func (h *Hub) SendToClient(clientID uuid.UUID, message interface{}) {
 
 	data, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("Failed to marshal message: %v", err)
+		slog.Error("Failed to marshal message", "client_id", clientID, "error", err)
 		return
 	}
 
@@ -0,0 +1,31 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHub_AgentViewers(t *testing.T) {
+	h := NewHub()
+	gameID := uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+	client := func(agentID *uuid.UUID) *Client {
+		c := &Client{ID: uuid.New(), GameID: gameID, PlayerAgentID: agentID, Send: make(chan []byte, 1), hub: h}
+		h.registerClient(c)
+		return c
+	}
+	spectator, alicePlayer, bobPlayer := client(nil), client(&alice), client(&bob)
+
+	if got := h.GetAgentViewerCount(gameID, alice); got != 2 {
+		t.Errorf("expected the spectator and alice's player to view alice, got %d", got)
+	}
+	h.broadcastToGame(BroadcastMessage{GameID: gameID, Message: "thinking", AgentID: &alice})
+
+	if len(spectator.Send) != 1 || len(alicePlayer.Send) != 1 {
+		t.Error("expected the spectator and alice's player to receive alice's reasoning")
+	}
+	if len(bobPlayer.Send) != 0 {
+		t.Error("expected bob's player not to receive alice's reasoning")
+	}
+}
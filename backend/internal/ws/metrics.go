@@ -0,0 +1,27 @@
+package ws
+
+import (
+	"github.com/lucas/promptlands/internal/metrics"
+)
+
+var (
+	queueDepth = metrics.Default.NewGauge("promptlands_ws_queue_depth",
+		"Messages waiting in the hub's queues.", "queue")
+	broadcastBytes = metrics.Default.NewCounter("promptlands_ws_broadcast_bytes_total",
+		"Bytes queued to WebSocket clients by broadcasts.")
+	slowClients = metrics.Default.NewCounter("promptlands_ws_slow_clients_total",
+		"Clients disconnected because their send buffer was full.")
+)
+
+// RegisterMetrics exposes the connected client count on r
+func (h *Hub) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc("promptlands_ws_clients", "Connected WebSocket clients.", func() float64 {
+		return float64(h.GetClientCount())
+	})
+}
+
+// observeQueues records the current depth of the broadcast queues
+func (h *Hub) observeQueues() {
+	queueDepth.Set(float64(len(h.broadcast)), "broadcast")
+	queueDepth.Set(float64(len(h.perPlayerBroadcast)), "per_player")
+}